@@ -1,15 +1,33 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 	"github.com/rs/zerolog"
 
 	"github.com/d-nery/catorce/pkg/bot"
+	"github.com/d-nery/catorce/pkg/deck"
+	"github.com/d-nery/catorce/pkg/game"
+	"github.com/d-nery/catorce/pkg/sim"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "verify":
+			os.Exit(verify(os.Args[2:]))
+		case "simulate":
+			os.Exit(simulate(os.Args[2:]))
+		case "fsm-graph":
+			os.Exit(fsmGraph(os.Args[2:]))
+		}
+	}
+
 	logger := zerolog.New(zerolog.NewConsoleWriter()).
 		With().
 		Timestamp().
@@ -43,3 +61,85 @@ func main() {
 
 	b.Start()
 }
+
+// verify checks a deck proof sent by the bot at the end of a game
+func verify(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: catorce verify <proof.json>")
+		return 2
+	}
+
+	proof, err := deck.ReadProof(args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if err := proof.Verify(); err != nil {
+		fmt.Fprintf(os.Stderr, "INVALID: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("OK: commitment %s matches, %d deck events verified\n", proof.Commitment, len(proof.Log))
+	return 0
+}
+
+// simulate runs games between computer-controlled players and reports how they went
+func simulate(args []string) int {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	games := fs.Int("n", 1000, "number of games")
+	players := fs.Int("players", 4, "players per game")
+	strategies := fs.String("strategy", "dificil", "comma separated strategies, assigned to players in order")
+	configPath := fs.String("config", "", "JSON game config file, defaults are used if empty")
+	seed := fs.Int64("seed", 0, "seed of the first game, random if 0")
+	fs.Parse(args)
+
+	config := game.DefaultConfig()
+
+	if *configPath != "" {
+		body, err := os.ReadFile(*configPath)
+		if err == nil {
+			err = json.Unmarshal(body, config)
+		}
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	report, err := sim.Run(sim.Options{
+		Games:      *games,
+		Players:    *players,
+		Strategies: strings.Split(*strategies, ","),
+		Config:     config,
+		Seed:       *seed,
+	})
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Print(report)
+	return 0
+}
+
+// fsmGraph prints the game state machine as a diagram
+func fsmGraph(args []string) int {
+	fs := flag.NewFlagSet("fsm-graph", flag.ExitOnError)
+	format := fs.String("format", "mermaid", "diagram format, mermaid or dot")
+	fs.Parse(args)
+
+	switch *format {
+	case "mermaid":
+		fmt.Print(game.Mermaid())
+	case "dot":
+		fmt.Print(game.Graphviz())
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format %q, use mermaid or dot\n", *format)
+		return 2
+	}
+
+	return 0
+}
@@ -0,0 +1,122 @@
+// Package ai implements computer-controlled players
+package ai
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/d-nery/catorce/pkg/deck"
+	"github.com/d-nery/catorce/pkg/game"
+)
+
+// Strategy decides the moves of a computer-controlled player
+// Every method is called with the game locked and the player being the one to decide
+type Strategy interface {
+	// ChooseCard returns the card to play, nil to draw (or pass after drawing)
+	ChooseCard(g *game.Game, p *game.Player) *deck.Card
+	// ChooseColor returns the color for a wild card
+	ChooseColor(g *game.Game, p *game.Player) deck.Color
+	// ChooseTarget returns the ID of the player to swap hands with
+	ChooseTarget(g *game.Game, p *game.Player) int
+	// Challenge decides whether to challenge a +4
+	Challenge(g *game.Game, p *game.Player) bool
+	// CallCatorce decides whether to call catorce when left with one card
+	CallCatorce(g *game.Game, p *game.Player) bool
+}
+
+// ErrNotAITurn is returned when it's not a computer-controlled player's turn
+var ErrNotAITurn = errors.New("ai: current player is not computer-controlled")
+
+// Strategies maps difficulty names to strategies
+var Strategies = map[string]Strategy{
+	"facil":   Random{},
+	"dificil": Heuristic{},
+}
+
+// DefaultStrategy is used when no difficulty is given
+const DefaultStrategy = "dificil"
+
+// ParseStrategy returns the strategy name for a difficulty, accents and case are ignored
+func ParseStrategy(s string) (string, bool) {
+	name := strings.NewReplacer("á", "a", "í", "i").Replace(strings.ToLower(s))
+
+	if name == "" {
+		name = DefaultStrategy
+	}
+
+	_, ok := Strategies[name]
+	return name, ok
+}
+
+// Playable returns the cards in the player's hand that can be played now
+func Playable(g *game.Game, p *game.Player) []*deck.Card {
+	playable := []*deck.Card{}
+
+	for _, c := range p.Hand {
+		if c.CanPlayOnTop(g.GetCurrentCard(), g.DrawCounter() > 0, g.Config.StackConfig) {
+			playable = append(playable, c)
+		}
+	}
+
+	return playable
+}
+
+// Move makes a single decision for the current player, which must be computer-controlled
+// Returns the public plays made, a move may have more than one (e.g. a card and a catorce call)
+func Move(g *game.Game) ([]game.Play, error) {
+	p := g.CurrentPlayer()
+	if p == nil || !p.IsAI() {
+		return nil, ErrNotAITurn
+	}
+
+	s, ok := Strategies[p.Strategy]
+	if !ok {
+		s = Strategies[DefaultStrategy]
+	}
+
+	var evt interface{}
+
+	switch g.GetState() {
+	case game.CHOOSE_CARD, game.DREW, game.CHALLENGE:
+		// Whoever played the +4 may have left the game, then it can't be challenged
+		challengeable := g.GetState() == game.CHALLENGE && g.GetPlayer(g.PreviousPlayer) != nil
+
+		if challengeable && s.Challenge(g, p) {
+			evt = &game.EvtChallenge{Player: p}
+		} else if c := s.ChooseCard(g, p); c != nil {
+			evt = &game.EvtCardPlayed{Player: p, Card: c}
+		} else if g.GetState() == game.DREW {
+			evt = &game.EvtPass{Player: p}
+		} else {
+			evt = &game.EvtDrawCard{Player: p}
+		}
+
+	case game.CHOOSE_COLOR:
+		evt = &game.EvtColorChosen{Player: p, Color: s.ChooseColor(g, p)}
+
+	case game.CHOOSE_PLAYER:
+		evt = &game.EvtPlayerSwapChosen{Player: p, Target: s.ChooseTarget(g, p)}
+
+	default:
+		return nil, game.ErrEventNotCovered
+	}
+
+	if err := g.FireEvent(evt); err != nil {
+		return nil, err
+	}
+
+	plays := []game.Play{lastPlay(g)}
+
+	if g.MustCallCatorce(p) && s.CallCatorce(g, p) {
+		if err := g.FireEvent(&game.EvtCatorce{Player: p}); err == nil {
+			plays = append(plays, lastPlay(g))
+		}
+	}
+
+	return plays, nil
+}
+
+// lastPlay returns the last public play of the game
+func lastPlay(g *game.Game) game.Play {
+	return g.RecentPlays[len(g.RecentPlays)-1]
+}
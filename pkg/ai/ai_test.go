@@ -0,0 +1,162 @@
+package ai_test
+
+import (
+	"testing"
+
+	"github.com/d-nery/catorce/pkg/ai"
+	"github.com/d-nery/catorce/pkg/deck"
+	"github.com/d-nery/catorce/pkg/game"
+	"github.com/rs/zerolog"
+)
+
+// newGame starts a game between computers playing strategies, seated in order with IDs -1, -2, ...
+func newGame(t *testing.T, config *game.Config, seed int64, strategies ...string) *game.Game {
+	t.Helper()
+
+	config.RandomOrder = false
+	g := game.New(1, zerolog.Nop(), config)
+
+	for i, s := range strategies {
+		p := game.NewPlayer(-(i + 1), "Robô", "")
+		p.Strategy = s
+
+		if err := g.FireEvent(&game.EvtAddPlayer{Player: p}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := g.FireEvent(&game.EvtStartGame{Seed: seed}); err != nil {
+		t.Fatal(err)
+	}
+
+	return g
+}
+
+// setTurn makes it the first player's turn to play on top, with hand
+func setTurn(g *game.Game, top *deck.Card, hand ...*deck.Card) *game.Player {
+	for g.CurrentPlayer().ID != -1 {
+		g.NextPlayer()
+	}
+
+	g.State = game.CHOOSE_CARD
+	g.DrawCount = 0
+	g.CurrentCard = top
+
+	p := g.CurrentPlayer()
+	p.Hand = hand
+	for _, c := range append(hand, top) {
+		g.Deck.AssignID(c)
+	}
+
+	return p
+}
+
+func move(t *testing.T, g *game.Game) []game.Play {
+	t.Helper()
+
+	plays, err := ai.Move(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return plays
+}
+
+func TestMoveFullGames(t *testing.T) {
+	config := game.DefaultConfig()
+	config.Challenge = true
+	config.SevenO = true
+	config.DeckConfig.SetColoredAmount(game.SKIPTWO, -1, 1)
+	config.DeckConfig.SetAmount(deck.BLACK, deck.WILD|game.ROULETTE, -1, 2)
+	config.DeckConfig.SetAmount(deck.BLACK, deck.WILD|deck.SWAP, -1, 2)
+
+	for _, strategy := range []string{"facil", "dificil"} {
+		for seed := int64(1); seed <= 20; seed++ {
+			g := newGame(t, config, seed, strategy, strategy, strategy)
+
+			moves := 0
+			for ; moves < 5000 && g.GetState() != game.LOBBY; moves++ {
+				if plays := move(t, g); len(plays) == 0 {
+					t.Fatalf("%s, seed %d: move %d made no plays", strategy, seed, moves)
+				}
+			}
+
+			if g.GetState() != game.LOBBY || g.Winner == 0 {
+				t.Errorf("%s, seed %d: game not over after %d moves", strategy, seed, moves)
+			}
+		}
+	}
+}
+
+func TestMoveNotAITurn(t *testing.T) {
+	g := game.New(1, zerolog.Nop(), game.DefaultConfig())
+
+	for _, id := range []int{1, -1} {
+		if err := g.FireEvent(&game.EvtAddPlayer{Player: game.NewPlayer(id, "Player", "")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := g.FireEvent(&game.EvtStartGame{Seed: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	for g.CurrentPlayer().ID != 1 {
+		g.NextPlayer()
+	}
+
+	if _, err := ai.Move(g); err != ai.ErrNotAITurn {
+		t.Errorf("move on a person's turn returned %v, want %v", err, ai.ErrNotAITurn)
+	}
+}
+
+func TestHeuristic(t *testing.T) {
+	t.Run("attacks a player close to winning", func(t *testing.T) {
+		// Draws are worth less than the number, so they're only played to attack
+		config := game.DefaultConfig()
+		config.Scores = deck.ScoreTable{deck.DRAW: 1}
+
+		for _, threat := range []bool{false, true} {
+			g := newGame(t, config, 1, "dificil", "dificil")
+			nine, draw := deck.NewCard(deck.BLUE, deck.NUMBER, 9), deck.NewCard(deck.BLUE, deck.DRAW, 2)
+			setTurn(g, deck.NewCard(deck.BLUE, deck.NUMBER, 7), nine, draw, deck.NewCard(deck.RED, deck.NUMBER, 1))
+
+			want := nine
+			if threat {
+				want = draw
+				g.Players[1].Hand = g.Players[1].Hand[:2]
+			}
+
+			move(t, g)
+			if g.CurrentCard != want {
+				t.Errorf("played %s with the next player holding %d cards, want %s", g.CurrentCard, len(g.Players[1].Hand), want)
+			}
+		}
+	})
+
+	t.Run("picks the color it holds the most", func(t *testing.T) {
+		g := newGame(t, game.DefaultConfig(), 1, "dificil", "dificil")
+		wild := deck.NewCard(deck.BLACK, deck.WILD, -1)
+		p := setTurn(g, deck.NewCard(deck.GREEN, deck.NUMBER, 7),
+			wild, deck.NewCard(deck.BLUE, deck.NUMBER, 1), deck.NewCard(deck.BLUE, deck.NUMBER, 2), deck.NewCard(deck.RED, deck.NUMBER, 3))
+
+		move(t, g)
+		if g.CurrentCard != wild || g.GetState() != game.CHOOSE_COLOR || g.CurrentPlayer() != p {
+			t.Fatalf("played %s and the turn is %s, want to choose a color for %s", g.CurrentCard, g.GetState(), wild)
+		}
+
+		move(t, g)
+		if wild.Color != deck.BLUE {
+			t.Errorf("chose %s, want %s", wild.Color, deck.BLUE)
+		}
+	})
+
+	t.Run("calls catorce", func(t *testing.T) {
+		g := newGame(t, game.DefaultConfig(), 1, "dificil", "dificil")
+		p := setTurn(g, deck.NewCard(deck.RED, deck.NUMBER, 7), deck.NewCard(deck.RED, deck.NUMBER, 5), deck.NewCard(deck.BLUE, deck.NUMBER, 3))
+
+		if plays := move(t, g); len(plays) != 2 || g.MustCallCatorce(p) {
+			t.Errorf("made %d plays, pending catorce is %v, want a card and a catorce call", len(plays), g.MustCallCatorce(p))
+		}
+	})
+}
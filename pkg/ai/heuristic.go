@@ -0,0 +1,97 @@
+package ai
+
+import (
+	"github.com/d-nery/catorce/pkg/deck"
+	"github.com/d-nery/catorce/pkg/game"
+)
+
+// Heuristic plays like a careful person:
+//   - attacks with draws, skips and reverses when the next player is close to winning
+//   - otherwise gets rid of its most valuable colored cards, in the color it holds the most
+//   - keeps wild cards for when nothing else can be played
+//   - picks the color it holds the most, swaps with whoever has the fewest cards
+//   - always calls catorce
+type Heuristic struct{}
+
+func (Heuristic) ChooseCard(g *game.Game, p *game.Player) *deck.Card {
+	var best *deck.Card
+	bestScore := 0
+
+	threat := len(nextPlayer(g).Hand) <= 2
+	colors := colorCount(p)
+
+	for _, c := range Playable(g, p) {
+		score := 100 + c.Score(g.Config.Scores) + colors[c.Color]
+
+		if c.Type.Has(deck.WILD) {
+			score = 1
+		}
+
+		if threat && c.Type.Has(deck.DRAW|deck.SKIP|deck.SKIPALL|deck.REVERSE|game.SKIPTWO|game.ROULETTE) {
+			score += 1000
+		}
+
+		if best == nil || score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+
+	return best
+}
+
+func (Heuristic) ChooseColor(g *game.Game, p *game.Player) deck.Color {
+	colors := colorCount(p)
+	best := deck.PlayableColors[0]
+
+	for _, c := range deck.PlayableColors {
+		if colors[c] > colors[best] {
+			best = c
+		}
+	}
+
+	return best
+}
+
+func (Heuristic) ChooseTarget(g *game.Game, p *game.Player) int {
+	best := g.Players[1]
+
+	for _, t := range g.Players[1:] {
+		if len(t.Hand) < len(best.Hand) {
+			best = t
+		}
+	}
+
+	return best.ID
+}
+
+// Challenge bets that whoever played the +4 had a card of the previous color when their hand is big
+func (Heuristic) Challenge(g *game.Game, p *game.Player) bool {
+	previous := g.GetPlayer(g.PreviousPlayer)
+	return previous != nil && len(previous.Hand) >= 5
+}
+
+func (Heuristic) CallCatorce(g *game.Game, p *game.Player) bool {
+	return true
+}
+
+// nextPlayer returns the player after the current one
+func nextPlayer(g *game.Game) *game.Player {
+	if len(g.Players) < 2 {
+		return g.Players[0]
+	}
+
+	return g.Players[1]
+}
+
+// colorCount counts the colored cards of each color in the player's hand
+func colorCount(p *game.Player) map[deck.Color]int {
+	colors := map[deck.Color]int{}
+
+	for _, c := range p.Hand {
+		if !c.IsSpecial() {
+			colors[c.Color] += 1
+		}
+	}
+
+	return colors
+}
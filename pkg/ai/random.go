@@ -0,0 +1,38 @@
+package ai
+
+import (
+	"github.com/d-nery/catorce/pkg/deck"
+	"github.com/d-nery/catorce/pkg/game"
+)
+
+// Random plays any legal card, chooses colors and targets at random and sometimes forgets to call catorce
+// Randomness comes from the game's AI source, so seeded games stay reproducible without touching the journaled one
+type Random struct{}
+
+func (Random) ChooseCard(g *game.Game, p *game.Player) *deck.Card {
+	playable := Playable(g, p)
+
+	// Draw once in a while even with a playable card, but never after drawing
+	if len(playable) == 0 || (g.GetState() == game.CHOOSE_CARD && g.AIRand().Intn(10) == 0) {
+		return nil
+	}
+
+	return playable[g.AIRand().Intn(len(playable))]
+}
+
+func (Random) ChooseColor(g *game.Game, p *game.Player) deck.Color {
+	return deck.PlayableColors[g.AIRand().Intn(len(deck.PlayableColors))]
+}
+
+func (Random) ChooseTarget(g *game.Game, p *game.Player) int {
+	targets := g.Players[1:]
+	return targets[g.AIRand().Intn(len(targets))].ID
+}
+
+func (Random) Challenge(g *game.Game, p *game.Player) bool {
+	return g.AIRand().Intn(2) == 0
+}
+
+func (Random) CallCatorce(g *game.Game, p *game.Player) bool {
+	return g.AIRand().Intn(4) != 0
+}
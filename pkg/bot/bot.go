@@ -2,8 +2,12 @@ package bot
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/d-nery/catorce/pkg/game"
@@ -16,14 +20,25 @@ var Version string = "DEV"
 // Bot is the main bot struct, it manages all running games and telegram communication
 // Should only be created via New
 type Bot struct {
-	tb      *tb.Bot
-	Games   map[int64]*game.Game   // Maps chats to games
-	Players map[int]int64          // Maps players to chats
-	Configs map[int64]*game.Config // Persists chat configs accross games
+	tb         *tb.Bot
+	Games      map[int64]*game.Game   // Maps chats to games
+	Players    map[int]int64          // Maps players to chats
+	Configs    map[int64]*game.Config // Persists chat configs accross games
+	Matches    map[int64]*game.Match  // Matches in progress for each chat
+	Seeds      map[int64]int64        // Seed of the last finished game in each chat
+	Spectators map[int]int64          // Maps spectators to the chat they're watching
 
 	stats            OverallStats
 	catorceBtnMarkup *tb.ReplyMarkup
+	catchBtnMarkup   *tb.ReplyMarkup // Only the catch button, for computers that can't press CATORCE!
 	logger           zerolog.Logger
+	won              map[int64]game.GameWon // Games won by their last event, see AfterMove
+
+	timers   map[int64]*time.Timer // Turn timeouts for each chat
+	timersMx sync.Mutex
+
+	// Taken by every handler and timer callback, they run on their own goroutines and share the maps above
+	mx sync.Mutex
 }
 
 // New creates a new bot from a token and logger
@@ -38,13 +53,18 @@ func New(token string, logger zerolog.Logger) (*Bot, error) {
 	}
 
 	return &Bot{
-		tb:      b,
-		Games:   make(map[int64]*game.Game),
-		Players: make(map[int]int64),
-		Configs: make(map[int64]*game.Config),
-		stats:   make(OverallStats),
+		tb:         b,
+		Games:      make(map[int64]*game.Game),
+		Players:    make(map[int]int64),
+		Configs:    make(map[int64]*game.Config),
+		Matches:    make(map[int64]*game.Match),
+		Seeds:      make(map[int64]int64),
+		Spectators: make(map[int]int64),
+		stats:      make(OverallStats),
 
 		logger: logger,
+		won:    make(map[int64]game.GameWon),
+		timers: make(map[int64]*time.Timer),
 	}, nil
 }
 
@@ -52,19 +72,31 @@ func New(token string, logger zerolog.Logger) (*Bot, error) {
 func (b *Bot) SetupHandlers() {
 	b.catorceBtnMarkup = &tb.ReplyMarkup{}
 	btnCatorce := b.catorceBtnMarkup.Data("CATORCE!", "catorce")
-	b.catorceBtnMarkup.Inline(b.catorceBtnMarkup.Row(btnCatorce))
-
-	b.tb.Handle("/new", b.GroupOnly(b.HandleNew))
-	b.tb.Handle("/help", b.HandleHelp)
-	b.tb.Handle("/join", b.GroupOnly(b.HandleJoin))
-	b.tb.Handle("/kill", b.GroupOnly(b.AdminOnly(b.HandleKill)))
-	b.tb.Handle("/config", b.GroupOnly(b.AdminOnly(b.HandleConfig)))
-	b.tb.Handle("/start", b.GroupOnly(b.HandleStart))
-	b.tb.Handle("/stats", b.GroupOnly(b.HandleStats))
-	b.tb.Handle("/statsself", b.GroupOnly(b.HandleSelfStats))
-	b.tb.Handle(tb.OnChosenInlineResult, b.HandleResult)
-	b.tb.Handle(tb.OnQuery, b.HandleQuery)
-	b.tb.Handle(&btnCatorce, b.HandleCatorce)
+	btnCatch := b.catorceBtnMarkup.Data("Pegar! 🫵", "catch")
+	b.catorceBtnMarkup.Inline(b.catorceBtnMarkup.Row(btnCatorce, btnCatch))
+
+	b.catchBtnMarkup = &tb.ReplyMarkup{}
+	b.catchBtnMarkup.Inline(b.catchBtnMarkup.Row(btnCatch))
+
+	b.tb.Handle("/new", serial(b, b.GroupOnly(b.HandleNew)))
+	b.tb.Handle("/help", serial(b, b.HandleHelp))
+	b.tb.Handle("/join", serial(b, b.GroupOnly(b.HandleJoin)))
+	b.tb.Handle("/addbot", serial(b, b.GroupOnly(b.HandleAddBot)))
+	b.tb.Handle("/leave", serial(b, b.GroupOnly(b.HandleLeave)))
+	b.tb.Handle("/team", serial(b, b.GroupOnly(b.HandleTeam)))
+	b.tb.Handle("/spectate", serial(b, b.GroupOnly(b.HandleSpectate)))
+	b.tb.Handle("/kill", serial(b, b.GroupOnly(b.AdminOnly(b.HandleKill))))
+	b.tb.Handle("/undo", serial(b, b.GroupOnly(b.AdminOnly(b.HandleUndo))))
+	b.tb.Handle("/config", serial(b, b.GroupOnly(b.AdminOnly(b.HandleConfig))))
+	b.tb.Handle("/start", serial(b, b.GroupOnly(b.HandleStart)))
+	b.tb.Handle("/stats", serial(b, b.GroupOnly(b.HandleStats)))
+	b.tb.Handle("/match", serial(b, b.GroupOnly(b.HandleMatch)))
+	b.tb.Handle("/statsself", serial(b, b.GroupOnly(b.HandleSelfStats)))
+	b.tb.Handle("/seed", serial(b, b.GroupOnly(b.AdminOnly(b.HandleSeed))))
+	b.tb.Handle(tb.OnChosenInlineResult, serial(b, b.HandleResult))
+	b.tb.Handle(tb.OnQuery, serial(b, b.HandleQuery))
+	b.tb.Handle(&btnCatorce, serial(b, b.HandleCatorce))
+	b.tb.Handle(&btnCatch, serial(b, b.HandleCatch))
 
 	// b.tb.Handle(tb.OnSticker, func(m *tb.Message) {
 	// 	b.logger.Printf("STICKER %+v", m.Sticker)
@@ -74,29 +106,80 @@ func (b *Bot) SetupHandlers() {
 
 // Load loads bot data from the persistance file
 func (b *Bot) Load() {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
 	body, err := os.ReadFile("data/data.json")
 
 	if err != nil {
 		b.logger.Error().Err(err).Msg("Failed to load")
-		return
-	}
-
-	err = json.Unmarshal(body, b)
 
-	if err != nil {
-		b.logger.Error().Err(err).Msg("Failed to load")
-		return
+		if !errors.Is(err, fs.ErrNotExist) {
+			b.RecoverFromJournals()
+		}
+	} else if err = b.decode(body); err != nil {
+		b.logger.Error().Err(err).Msg("Failed to load, recovering games from journals")
+		b.RecoverFromJournals()
 	}
 
-	b.stats, err = ReadStatsFromFile("data/stats.json")
+	stats, err := ReadStatsFromFile("data/stats.json")
 
 	if err != nil {
 		b.logger.Error().Err(err).Msg("Failed to load")
+		stats = nil
 	}
 
+	b.stats = orEmpty(stats)
+
 	for _, g := range b.Games {
 		g.SetLogger(b.logger)
+		g.AssignCardIDs()
+		b.Watch(g)
+
+		// Chat configs and game configs are unmarshaled separately, make sure they're the same again
+		if cfg, ok := b.Configs[g.Chat]; ok {
+			g.SetConfig(cfg)
+		}
+
+		if g.JournalPath != "" {
+			if err := g.OpenJournal(g.JournalPath); err != nil {
+				b.logger.Error().Err(err).Int64("chat_id", g.Chat).Msg("Failed to reopen journal")
+			}
+		}
+
+		b.ArmTimer(g)
+	}
+}
+
+// decode replaces the persisted maps with the ones in body
+// The bot is left untouched if body is invalid, maps missing or null in it are left empty
+func (b *Bot) decode(body []byte) error {
+	var loaded Bot
+	if err := json.Unmarshal(body, &loaded); err != nil {
+		return err
+	}
+
+	b.Games = orEmpty(loaded.Games)
+	maps.DeleteFunc(b.Games, func(chat int64, g *game.Game) bool {
+		return g == nil
+	})
+
+	b.Players = orEmpty(loaded.Players)
+	b.Configs = orEmpty(loaded.Configs)
+	b.Matches = orEmpty(loaded.Matches)
+	b.Seeds = orEmpty(loaded.Seeds)
+	b.Spectators = orEmpty(loaded.Spectators)
+
+	return nil
+}
+
+// orEmpty returns m, or an empty map if it's nil
+func orEmpty[K comparable, V any](m map[K]V) map[K]V {
+	if m == nil {
+		return make(map[K]V)
 	}
+
+	return m
 }
 
 // Persist persists bot data to the persistance file
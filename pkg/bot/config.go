@@ -0,0 +1,289 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/d-nery/catorce/pkg/deck"
+	"github.com/d-nery/catorce/pkg/game"
+)
+
+// configOption is a chat setting that can be changed with /config
+type configOption struct {
+	description string
+	get         func(c *game.Config) string
+	set         func(c *game.Config, value string) error
+}
+
+// Possible config errors
+var (
+	ErrUnknownOption = errors.New("Opção desconhecida! /config para ver as opções")
+	ErrInvalidValue  = errors.New("Valor inválido!")
+)
+
+// configErrors maps game config validation errors to user messages
+var configErrors = map[error]string{
+	game.ErrInvalidPlayerLimits: "O máximo de jogadores não pode ser menor que o mínimo!",
+	game.ErrInvalidHandSize:     "Cada jogador precisa começar com pelo menos uma carta!",
+	game.ErrNotEnoughCards:      "Não há cartas suficientes no baralho para o máximo de jogadores!",
+	game.ErrInvalidPenalty:      "A multa do catorce precisa ser de pelo menos uma carta!",
+	game.ErrInvalidCardType:     "O baralho tem cartas de tipos inválidos!",
+}
+
+var configOptions = map[string]configOption{
+	"tempo": durationOption("Tempo máximo de cada jogada (ex: 30m, 2h), 0 desativa", func(c *game.Config) *time.Duration {
+		return &c.TurnTimeout
+	}),
+	"skipall":    cardAmountOption("Quantidade de cartas que pulam todos, por cor", deck.SKIPALL),
+	"swapall":    cardAmountOption("Quantidade de cartas que giram todas as mãos, por cor", deck.SWAPALL),
+	"discardall": cardAmountOption("Quantidade de cartas que descartam todas da mesma cor, por cor", deck.DISCARDALL),
+	"skiptwo":    cardAmountOption("Quantidade de cartas que pulam os próximos dois jogadores, por cor", game.SKIPTWO),
+	"roulette":   wildAmountOption("Quantidade de coringas roleta: o próximo puxa até tirar a cor escolhida", game.ROULETTE),
+	"seteo": boolOption("Regra Seven-O: 7 troca de mão com alguém e 0 gira todas as mãos (sim/não)", func(c *game.Config) *bool {
+		return &c.SevenO
+	}),
+	"cortar": boolOption("Permite jogar uma carta idêntica à da mesa fora da sua vez (sim/não)", func(c *game.Config) *bool {
+		return &c.JumpIn
+	}),
+	"puxar": boolOption("Continua puxando até conseguir jogar (sim/não)", func(c *game.Config) *bool {
+		return &c.DrawUntilPlayable
+	}),
+	"limite_puxar": intOption("Máximo de cartas puxadas de uma vez quando puxar até conseguir jogar", 1, func(c *game.Config) *int {
+		return &c.DrawLimit
+	}),
+	"desafio": boolOption("Permite desafiar um +4 jogado sem necessidade (sim/não)", func(c *game.Config) *bool {
+		return &c.Challenge
+	}),
+	"pontos": {
+		description: "Pontos de cada tipo de carta, altere com <tipo>=<pontos> (ex: wild-draw=50)",
+		get: func(c *game.Config) string {
+			entries := make([]string, 0, len(c.Scores))
+			for t, v := range c.Scores {
+				entries = append(entries, fmt.Sprintf("%s=%d", t, v))
+			}
+			sort.Strings(entries)
+
+			return strings.Join(entries, ", ")
+		},
+		set: func(c *game.Config, value string) error {
+			name, points, ok := strings.Cut(value, "=")
+			if !ok {
+				return ErrInvalidValue
+			}
+
+			t, err := deck.ParseCardType(name)
+			if err != nil {
+				return ErrInvalidValue
+			}
+
+			v, err := strconv.Atoi(points)
+			if err != nil || v < 0 {
+				return ErrInvalidValue
+			}
+
+			if c.Scores == nil {
+				c.Scores = deck.DefaultScoreTable()
+			}
+
+			c.Scores[t] = v
+			return nil
+		},
+	},
+	"partida": intOption("Jogos seguidos formam uma partida até alguém atingir esses pontos, 0 desativa", 0, func(c *game.Config) *int {
+		return &c.MatchTarget
+	}),
+	"equipes": boolOption("Modo de equipes: dois times sentados alternadamente, /team para escolher (sim/não)", func(c *game.Config) *bool {
+		return &c.TeamMode
+	}),
+	"entrar": boolOption("Permite entrar em um jogo que já começou (sim/não)", func(c *game.Config) *bool {
+		return &c.LateJoin
+	}),
+	"min_jogadores": intOption("Quantidade mínima de jogadores para começar", 2, func(c *game.Config) *int {
+		return &c.MinPlayers
+	}),
+	"max_jogadores": intOption("Quantidade máxima de jogadores", 2, func(c *game.Config) *int {
+		return &c.MaxPlayers
+	}),
+	"cartas_iniciais": intOption("Quantidade de cartas de cada jogador no começo do jogo", 1, func(c *game.Config) *int {
+		return &c.HandSize
+	}),
+	"ordem_aleatoria": boolOption("Sorteia a ordem dos jogadores, senão joga na ordem de entrada (sim/não)", func(c *game.Config) *bool {
+		return &c.RandomOrder
+	}),
+	"multa_catorce": intOption("Cartas puxadas por quem não chama CATORCE! a tempo", 1, func(c *game.Config) *int {
+		return &c.CatorcePenalty
+	}),
+	"tolerancia_catorce": durationOption("Tempo para chamar CATORCE! antes que os outros possam pegar (ex: 5s)", func(c *game.Config) *time.Duration {
+		return &c.CatchGrace
+	}),
+}
+
+// parseBool parses yes/no values in portuguese or english
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "sim", "s", "yes", "y", "true", "1", "on":
+		return true, nil
+	case "não", "nao", "n", "no", "false", "0", "off":
+		return false, nil
+	}
+
+	return false, ErrInvalidValue
+}
+
+// formatBool formats a boolean as yes/no in portuguese
+func formatBool(value bool) string {
+	if value {
+		return "sim"
+	}
+
+	return "não"
+}
+
+// boolOption creates a yes/no option for the config field returned by field
+func boolOption(description string, field func(c *game.Config) *bool) configOption {
+	return configOption{
+		description: description,
+		get: func(c *game.Config) string {
+			return formatBool(*field(c))
+		},
+		set: func(c *game.Config, value string) error {
+			v, err := parseBool(value)
+
+			if err != nil {
+				return err
+			}
+
+			*field(c) = v
+			return nil
+		},
+	}
+}
+
+// intOption creates an integer option for the config field returned by field, with a minimum value
+func intOption(description string, min int, field func(c *game.Config) *int) configOption {
+	return configOption{
+		description: description,
+		get: func(c *game.Config) string {
+			return strconv.Itoa(*field(c))
+		},
+		set: func(c *game.Config, value string) error {
+			v, err := strconv.Atoi(value)
+
+			if err != nil || v < min {
+				return ErrInvalidValue
+			}
+
+			*field(c) = v
+			return nil
+		},
+	}
+}
+
+// durationOption creates a duration option (e.g. 30m, 2h) for the config field returned by field
+func durationOption(description string, field func(c *game.Config) *time.Duration) configOption {
+	return configOption{
+		description: description,
+		get: func(c *game.Config) string {
+			return field(c).String()
+		},
+		set: func(c *game.Config, value string) error {
+			d, err := time.ParseDuration(value)
+
+			if err != nil || d < 0 {
+				return ErrInvalidValue
+			}
+
+			*field(c) = d
+			return nil
+		},
+	}
+}
+
+// cardAmountOption creates an option for the amount of cards of type t per color in the deck
+func cardAmountOption(description string, t deck.CardType) configOption {
+	return configOption{
+		description: description,
+		get: func(c *game.Config) string {
+			return strconv.Itoa(c.DeckConfig.ColoredAmount(t, -1))
+		},
+		set: func(c *game.Config, value string) error {
+			v, err := strconv.Atoi(value)
+
+			if err != nil || v < 0 || v > 10 {
+				return ErrInvalidValue
+			}
+
+			c.DeckConfig.SetColoredAmount(t, -1, v)
+			return nil
+		},
+	}
+}
+
+// wildAmountOption creates an option for the amount of wild cards of type t in the deck
+func wildAmountOption(description string, t deck.CardType) configOption {
+	t |= deck.WILD
+
+	return configOption{
+		description: description,
+		get: func(c *game.Config) string {
+			return strconv.Itoa(c.DeckConfig.Cards[deck.CardData{Color: deck.BLACK, CardType: t, Value: -1}])
+		},
+		set: func(c *game.Config, value string) error {
+			v, err := strconv.Atoi(value)
+
+			if err != nil || v < 0 || v > 10 {
+				return ErrInvalidValue
+			}
+
+			c.DeckConfig.SetAmount(deck.BLACK, t, -1, v)
+			return nil
+		},
+	}
+}
+
+// SetConfigOption changes option to value on the config
+func SetConfigOption(c *game.Config, option, value string) error {
+	opt, ok := configOptions[option]
+
+	if !ok {
+		return ErrUnknownOption
+	}
+
+	// Changes are made on a copy, so an inconsistent result doesn't touch the chat config
+	clone := c.Clone()
+	if err := opt.set(clone, value); err != nil {
+		return err
+	}
+
+	if err := clone.Validate(); err != nil {
+		return errors.New(configErrors[err])
+	}
+
+	*c = *clone
+	return nil
+}
+
+// ConfigReport generates a Markdown formatted string with all config options and their current values
+func ConfigReport(c *game.Config) string {
+	var out strings.Builder
+
+	keys := make([]string, 0, len(configOptions))
+	for k := range configOptions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(&out, "*Configurações desse chat*\n\n")
+	for _, k := range keys {
+		opt := configOptions[k]
+		fmt.Fprintf(&out, "`%s` = %s\n_%s_\n\n", k, opt.get(c), opt.description)
+	}
+
+	fmt.Fprint(&out, "/config <opção> <valor> para alterar")
+
+	return out.String()
+}
@@ -0,0 +1,69 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/d-nery/catorce/pkg/deck"
+	"github.com/d-nery/catorce/pkg/game"
+)
+
+func TestSetConfigOption(t *testing.T) {
+	tests := []struct {
+		option, value string
+		check         func(c *game.Config) bool
+	}{
+		{"entrar", "sim", func(c *game.Config) bool { return c.LateJoin }},
+		{"desafio", "s", func(c *game.Config) bool { return c.Challenge }},
+		{"tempo", "30m", func(c *game.Config) bool { return c.TurnTimeout == 30*time.Minute }},
+		{"min_jogadores", "4", func(c *game.Config) bool { return c.MinPlayers == 4 }},
+		{"pontos", "wild-draw=40", func(c *game.Config) bool { return c.Scores[deck.WILD|deck.DRAW] == 40 }},
+		{"skiptwo", "2", func(c *game.Config) bool { return c.DeckConfig.ColoredAmount(game.SKIPTWO, -1) == 2 }},
+		{"roulette", "3", func(c *game.Config) bool {
+			return c.DeckConfig.Cards[deck.CardData{Color: deck.BLACK, CardType: deck.WILD | game.ROULETTE, Value: -1}] == 3
+		}},
+	}
+
+	for _, tt := range tests {
+		c := game.DefaultConfig()
+
+		if err := SetConfigOption(c, tt.option, tt.value); err != nil {
+			t.Errorf("%s %s: %v", tt.option, tt.value, err)
+			continue
+		}
+
+		if !tt.check(c) {
+			t.Errorf("%s %s wasn't set, got %s", tt.option, tt.value, configOptions[tt.option].get(c))
+		}
+	}
+}
+
+func TestSetConfigOptionRejected(t *testing.T) {
+	tests := []struct {
+		option, value string
+		err           string
+	}{
+		{"bogus", "sim", ErrUnknownOption.Error()},
+		{"entrar", "talvez", ErrInvalidValue.Error()},
+		{"tempo", "logo", ErrInvalidValue.Error()},
+		{"min_jogadores", "1", ErrInvalidValue.Error()},
+		{"pontos", "bogus=10", ErrInvalidValue.Error()},
+		// Valid values that leave an invalid config
+		{"min_jogadores", "11", configErrors[game.ErrInvalidPlayerLimits]},
+		{"cartas_iniciais", "20", configErrors[game.ErrNotEnoughCards]},
+	}
+
+	for _, tt := range tests {
+		c := game.DefaultConfig()
+
+		err := SetConfigOption(c, tt.option, tt.value)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("%s %s: got %v, want %q", tt.option, tt.value, err, tt.err)
+		}
+
+		if !reflect.DeepEqual(c, game.DefaultConfig()) {
+			t.Errorf("%s %s changed the config", tt.option, tt.value)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/d-nery/catorce/pkg/ai"
 	"github.com/d-nery/catorce/pkg/deck"
 	"github.com/d-nery/catorce/pkg/game"
 	tb "gopkg.in/tucnak/telebot.v2"
@@ -18,22 +19,39 @@ func (b *Bot) HandleHelp(m *tb.Message) {
 2. No grupo, comece uma nova partida com /new
 C. Para se juntar a uma partida use /join
 4ª O jogo deve ter pelo menos 2 jogadores antes de começar
-* Para jogar. Digite @catorce_uno_bot na caixa de mensagens ou clique no "via @catorce_uno_bot" ao lado das mensagens. Aguarde um pouco e você verá suas cartas. Cartas cinzas não podem ser jogadas. Se você não estiver na sua vez, todas as cartas serão cinzas.
+* Para jogar. Digite @catorce_uno_bot na caixa de mensagens ou clique no "via @catorce_uno_bot" ao lado das mensagens. Aguarde um pouco e você verá suas cartas. Cartas cinzas não podem ser jogadas. Se você não estiver na sua vez, todas as cartas serão cinzas (a não ser que o grupo permita cortar a vez com uma carta idêntica à da mesa, /config cortar sim).
 110 Selecionar uma carta cinza irá mostrar a atual situação do jogo.
-7- Ao ficar com uma unica carta sobrando, lembre-se de apertar no CATORCE!
+7- Ao ficar com uma unica carta sobrando, lembre-se de apertar no CATORCE! Se demorar, os outros jogadores podem te pegar no "Pegar!" e você puxa as cartas da multa.
 
-Jogadores não podem entrar após a partida começar. Caso um jogador demore mais que 6 horas pra jogar ele é um babaca.
+Jogadores só podem entrar após a partida começar se o grupo permitir (/config entrar sim). Caso um jogador demore demais pra jogar ele é um babaca, e se o grupo tiver um tempo limite configurado, o bot joga por ele (puxa uma carta e passa a vez).
 Caso o bot entre em colapso, não se preocupe, o estado do jogo é salvo e ao reiniciar, o bot recupera esse savepoint ;)
 
 Outros comandos:
 /stats - Mostra dados sobre os jogos do grupo interessantes
 /statsself - Mostra seus dados apenas
-/config - Configurações do jogo nesse chat (adm only)
-/kill - F game (adm only)`
+/leave - Sai do jogo atual (conta como jogo abandonado)
+/team - Escolhe o time no modo de equipes (/team a ou /team b)
+/addbot - Adiciona um robô ao jogo, antes de começar (/addbot fácil ou /addbot difícil)
+/spectate - Assiste o jogo do grupo, recebendo as jogadas no privado (use de novo para parar)
+/config - Configurações do jogo nesse chat, /config <opção> <valor> para alterar (adm only)
+/kill - F game (adm only)
+/undo - Desfaz a última jogada (adm only)
+/match - Mostra o placar da partida atual
+/seed - Mostra a semente do último jogo, /start <semente> repete o mesmo embaralhamento (adm only)`
 
 	b.tb.Send(m.Chat, helpMsg)
 }
 
+// serial makes f hold the bot's lock while it runs, see Bot.mx
+func serial[T any](b *Bot, f func(T)) func(T) {
+	return func(arg T) {
+		b.mx.Lock()
+		defer b.mx.Unlock()
+
+		f(arg)
+	}
+}
+
 func (b *Bot) GroupOnly(f func(*tb.Message)) func(m *tb.Message) {
 	return func(m *tb.Message) {
 		b.logger.Trace().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Msg("Group middleware accessed")
@@ -51,27 +69,34 @@ func (b *Bot) AdminOnly(f func(*tb.Message)) func(m *tb.Message) {
 	return func(m *tb.Message) {
 		b.logger.Trace().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Msg("Admin middleware accessed")
 
-		if m.Private() {
-			f(m)
+		if !b.IsAdmin(m) {
+			b.tb.Send(m.Chat, "Esse comando está disponível apenas para administradores")
 			return
 		}
 
-		cm, err := b.tb.ChatMemberOf(m.Chat, m.Sender)
+		f(m)
+	}
+}
 
-		if err != nil {
-			b.logger.Error().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Err(err).Msg("couldn't find chat member")
-			b.tb.Send(m.Chat, "Esse comando está disponível apenas para administradores")
-			return
-		}
+// IsAdmin checks if the message sender is an admin of the chat, everyone is admin in private chats
+func (b *Bot) IsAdmin(m *tb.Message) bool {
+	if m.Private() {
+		return true
+	}
 
-		if cm.Role != tb.Administrator && cm.Role != tb.Creator {
-			b.logger.Info().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Str("role", string(cm.Role)).Msg("user is not admin")
-			b.tb.Send(m.Chat, "Esse comando está disponível apenas para administradores")
-			return
-		}
+	cm, err := b.tb.ChatMemberOf(m.Chat, m.Sender)
 
-		f(m)
+	if err != nil {
+		b.logger.Error().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Err(err).Msg("couldn't find chat member")
+		return false
 	}
+
+	if cm.Role != tb.Administrator && cm.Role != tb.Creator {
+		b.logger.Info().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Str("role", string(cm.Role)).Msg("user is not admin")
+		return false
+	}
+
+	return true
 }
 
 // HandleNew handles /new requests
@@ -92,23 +117,19 @@ func (b *Bot) HandleNew(m *tb.Message) {
 	}
 
 	b.Games[m.Chat.ID] = game.New(m.Chat.ID, b.logger, b.Configs[m.Chat.ID])
+	b.Watch(b.Games[m.Chat.ID])
+	b.OpenJournal(b.Games[m.Chat.ID])
 
 	b.logger.Info().Int64("chat_id", m.Chat.ID).Msg("New game created")
 	b.logger.Trace().Int("games_len", len(b.Games)).Send()
 
-	if _, ok := b.stats[m.Chat.ID]; !ok {
-		b.logger.Info().Int64("chat_id", m.Chat.ID).Msg("No stats for current chat, creating")
-		b.stats[m.Chat.ID] = &ChatStats{
-			Group:   GroupStats{},
-			Players: make(map[int]*PlayerStats),
-		}
-	}
+	b.chatStats(m.Chat.ID)
 
 	b.tb.Send(m.Chat, "Jogo criado com sucesso!\n/join para entrar.")
 }
 
 // HandleJoin handles /join requests
-// Can only be used in groups during LOBBY state
+// Can only be used in groups during LOBBY state, or during the game if the chat allows late joins
 func (b *Bot) HandleJoin(m *tb.Message) {
 	b.logger.Info().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Msg("Join request received")
 
@@ -125,11 +146,18 @@ func (b *Bot) HandleJoin(m *tb.Message) {
 	}
 
 	g := b.Games[m.Chat.ID]
-	if err := g.FireEvent(&game.EvtAddPlayer{Player: game.NewPlayer(m.Sender.ID, m.Sender)}); err != nil {
+	g.Lock()
+	defer g.Unlock()
+
+	player := game.NewPlayer(m.Sender.ID, m.Sender.FirstName, m.Sender.Username)
+	if err := g.FireEvent(&game.EvtAddPlayer{Player: player}); err != nil {
 		b.logger.Error().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Err(err).Send()
 		switch err {
 		case game.ErrMaxPlayers:
-			b.tb.Send(m.Chat, "Máximo de jogadores atingido!")
+			b.tb.Send(m.Chat, fmt.Sprintf("Máximo de %d jogadores atingido!", g.Config.MaxPlayers))
+			return
+		case game.ErrEventNotCovered:
+			b.tb.Send(m.Chat, "O jogo já começou! Espere o próximo.")
 			return
 		default:
 			b.tb.Send(m.Chat, "Erro :(")
@@ -138,15 +166,246 @@ func (b *Bot) HandleJoin(m *tb.Message) {
 	}
 
 	b.Players[m.Sender.ID] = m.Chat.ID
+	delete(b.Spectators, m.Sender.ID)
+
+	if g.GetState() != game.LOBBY {
+		b.logger.Info().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Msg("Player joined running game")
+		b.tb.Send(m.Chat,
+			fmt.Sprintf(
+				"%s entrou no jogo em andamento com %d cartas! Vai jogar logo antes de %s.",
+				Mention(player),
+				len(player.Hand),
+				Mention(g.CurrentPlayer()),
+			),
+			tb.ModeMarkdown,
+		)
+
+		b.Persist()
+		return
+	}
+
+	b.tb.Send(m.Chat, "Entrando no jogo... "+LobbyReport(g))
+}
 
+// HandleAddBot handles /addbot requests
+// Can only be used in groups during LOBBY state, adds a computer-controlled player with the given difficulty
+func (b *Bot) HandleAddBot(m *tb.Message) {
+	b.logger.Info().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Msg("Add bot request received")
+
+	g, ok := b.Games[m.Chat.ID]
+	if !ok {
+		b.logger.Info().Int64("chat_id", m.Chat.ID).Msg("No game running on this chat")
+		b.tb.Send(m.Chat, "Não há nenhum jogo nesse chat! /new para criar um")
+		return
+	}
+
+	strategy, ok := ai.ParseStrategy(m.Payload)
+	if !ok {
+		b.tb.Send(m.Chat, "Dificuldade inválida! Uso: /addbot [fácil|difícil]")
+		return
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	if g.GetState() != game.LOBBY {
+		b.tb.Send(m.Chat, "O jogo já começou! Robôs só podem entrar antes.")
+		return
+	}
+
+	// Computer-controlled players get decreasing negative IDs
+	id := -1
+	for _, p := range g.Players {
+		if p.ID <= id {
+			id = p.ID - 1
+		}
+	}
+
+	player := game.NewPlayer(id, fmt.Sprintf("Robô %d", -id), "")
+	player.Strategy = strategy
+
+	if err := g.FireEvent(&game.EvtAddPlayer{Player: player}); err != nil {
+		b.logger.Error().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Err(err).Send()
+		switch err {
+		case game.ErrMaxPlayers:
+			b.tb.Send(m.Chat, fmt.Sprintf("Máximo de %d jogadores atingido!", g.Config.MaxPlayers))
+		default:
+			b.tb.Send(m.Chat, "Erro :(")
+		}
+		return
+	}
+
+	b.tb.Send(m.Chat, fmt.Sprintf("%s (%s) entrou no jogo! ", Mention(player), strategy)+LobbyReport(g), tb.ModeMarkdown)
+}
+
+// LobbyReport generates a string with the players currently in the game, and their teams in team mode
+func LobbyReport(g *game.Game) string {
 	var out strings.Builder
-	out.WriteString("Entrando no jogo... Jogadores atuais:\n")
+	out.WriteString("Jogadores atuais:\n")
 
 	for _, p := range g.PlayerList() {
-		fmt.Fprintf(&out, " • %s\n", p.Name)
+		if p.Team != game.NO_TEAM {
+			fmt.Fprintf(&out, " • %s (%s)\n", p.Name, TeamName(p.Team))
+		} else {
+			fmt.Fprintf(&out, " • %s\n", p.Name)
+		}
+	}
+
+	return out.String()
+}
+
+// TeamName returns the team display name
+func TeamName(team int) string {
+	switch team {
+	case game.TEAM_A:
+		return "Time A"
+	case game.TEAM_B:
+		return "Time B"
+	}
+
+	return "Sem time"
+}
+
+// HandleSpectate handles /spectate requests
+// Can only be used in groups by someone not playing, toggles receiving each move in private
+func (b *Bot) HandleSpectate(m *tb.Message) {
+	b.logger.Info().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Msg("Spectate request received")
+
+	g, ok := b.Games[m.Chat.ID]
+	if !ok {
+		b.logger.Info().Int64("chat_id", m.Chat.ID).Msg("No game running on this chat")
+		b.tb.Send(m.Chat, "Não há nenhum jogo nesse chat! /new para criar um")
+		return
+	}
+
+	if _, ok := b.Players[m.Sender.ID]; ok {
+		b.tb.Send(m.Chat, "Você já está participando de algum jogo!")
+		return
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	if b.Spectators[m.Sender.ID] == m.Chat.ID {
+		delete(b.Spectators, m.Sender.ID)
+		b.tb.Send(m.Chat, fmt.Sprintf("%s parou de assistir o jogo.", m.Sender.FirstName))
+		b.Persist()
+		return
+	}
+
+	msg := "👀 Você está assistindo o jogo! As jogadas vão chegar por aqui, /spectate no grupo para parar."
+	if g.GetState() != game.LOBBY {
+		msg += "\n\n" + SpectatorInfo(g)
+	}
+
+	if _, err := b.tb.Send(m.Sender, msg, tb.ModeMarkdown); err != nil {
+		b.logger.Info().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Err(err).Msg("Couldn't message spectator")
+		b.tb.Send(m.Chat, "Não consegui te mandar mensagem! Fale comigo no privado primeiro e tente de novo.")
+		return
+	}
+
+	b.Spectators[m.Sender.ID] = m.Chat.ID
+	b.tb.Send(m.Chat, fmt.Sprintf("%s está assistindo o jogo 👀", m.Sender.FirstName))
+	b.Persist()
+}
+
+// HandleTeam handles /team requests
+// Can only be used in groups during LOBBY state, "/team a" or "/team b" chooses a team, no argument switches teams
+func (b *Bot) HandleTeam(m *tb.Message) {
+	b.logger.Info().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Msg("Team request received")
+
+	g, ok := b.Games[m.Chat.ID]
+	if !ok {
+		b.logger.Info().Int64("chat_id", m.Chat.ID).Msg("No game running on this chat")
+		b.tb.Send(m.Chat, "Não há nenhum jogo nesse chat! /new para criar um")
+		return
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	player := g.GetPlayer(m.Sender.ID)
+	if player == nil {
+		b.tb.Send(m.Chat, "Você não está participando desse jogo! /join para entrar")
+		return
+	}
+
+	team := game.TEAM_A
+	switch strings.ToLower(strings.TrimSpace(m.Payload)) {
+	case "a":
+	case "b":
+		team = game.TEAM_B
+	case "":
+		if player.Team == game.TEAM_A {
+			team = game.TEAM_B
+		}
+	default:
+		b.tb.Send(m.Chat, "Uso: /team [a|b]")
+		return
+	}
+
+	if err := g.FireEvent(&game.EvtChooseTeam{Player: player, Team: team}); err != nil {
+		b.logger.Error().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Err(err).Send()
+		switch err {
+		case game.ErrNotTeamMode:
+			b.tb.Send(m.Chat, "O jogo não está no modo de equipes! /config equipes sim para ativar")
+		case game.ErrEventNotCovered:
+			b.tb.Send(m.Chat, "Não dá pra trocar de time com o jogo em andamento!")
+		default:
+			b.tb.Send(m.Chat, "Erro :(")
+		}
+		return
 	}
 
-	b.tb.Send(m.Chat, out.String())
+	b.tb.Send(m.Chat, LobbyReport(g))
+}
+
+// HandleLeave handles /leave requests
+// Can only be used in groups, removes the player from the chat's game
+func (b *Bot) HandleLeave(m *tb.Message) {
+	b.logger.Info().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Msg("Leave request received")
+
+	g, ok := b.Games[m.Chat.ID]
+	if !ok {
+		b.logger.Info().Int64("chat_id", m.Chat.ID).Msg("No game running on this chat")
+		b.tb.Send(m.Chat, "Não há nenhum jogo nesse chat! /new para criar um")
+		return
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	player := g.GetPlayer(m.Sender.ID)
+	if player == nil {
+		b.logger.Info().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Msg("Player not in this game")
+		b.tb.Send(m.Chat, "Você não está participando desse jogo!")
+		return
+	}
+
+	running := g.GetState() != game.LOBBY
+	wasCurrent := player == g.CurrentPlayer()
+
+	if err := g.FireEvent(&game.EvtRemovePlayer{Player: player}); err != nil {
+		b.logger.Error().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Err(err).Send()
+		b.tb.Send(m.Chat, "Erro :(")
+		return
+	}
+
+	delete(b.Players, m.Sender.ID)
+
+	if !running {
+		b.tb.Send(m.Chat, fmt.Sprintf("%s saiu do jogo. %s", player.Name, LobbyReport(g)))
+		return
+	}
+
+	if wasCurrent {
+		b.AfterMove(g)
+		return
+	}
+
+	if !b.finishGame(g) {
+		b.Persist()
+	}
 }
 
 // HandleStart handles /start requests
@@ -161,15 +420,38 @@ func (b *Bot) HandleStart(m *tb.Message) {
 		return
 	}
 
+	// Only admins can choose the seed, otherwise anyone could pick a known deal
+	var seed int64
+	if m.Payload != "" {
+		if !b.IsAdmin(m) {
+			b.tb.Send(m.Chat, "Apenas administradores podem escolher a semente do jogo")
+			return
+		}
+
+		s, err := strconv.ParseInt(m.Payload, 10, 64)
+		if err != nil || s == 0 {
+			b.tb.Send(m.Chat, "Semente inválida! Uso: /start [semente]")
+			return
+		}
+
+		seed = s
+	}
+
 	g := b.Games[m.Chat.ID]
 	g.Lock()
 	defer g.Unlock()
 
-	if err := g.FireEvent(&game.EvtStartGame{}); err != nil {
+	if err := g.FireEvent(&game.EvtStartGame{Seed: seed}); err != nil {
 		b.logger.Error().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Err(err).Send()
 		switch err {
 		case game.ErrNotEnoughPlayers:
-			b.tb.Send(m.Chat, "Não há jogadores suficientes! /join para entrar.")
+			b.tb.Send(m.Chat, fmt.Sprintf("São necessários pelo menos %d jogadores! /join para entrar.", g.Config.MinPlayers))
+			return
+		case game.ErrInvalidConfig:
+			b.tb.Send(m.Chat, "A configuração desse chat é inválida! /config para ver as opções.")
+			return
+		case game.ErrUnbalancedTeams:
+			b.tb.Send(m.Chat, "Os times precisam ter a mesma quantidade de jogadores! /team para trocar.\n"+LobbyReport(g))
 			return
 		case game.ErrEventNotCovered:
 			b.tb.Send(m.Chat, "Opa, acho que o jogo já começou!")
@@ -180,10 +462,22 @@ func (b *Bot) HandleStart(m *tb.Message) {
 		return
 	}
 
-	b.tb.Send(m.Chat, "Começando!")
-	b.tb.Send(m.Chat, g.CurrentCardSticker())
-	b.tb.Send(m.Chat, fmt.Sprintf("Jogador(a) Atual: %s", g.CurrentPlayer().NameWithMention()), tb.ModeMarkdown)
+	if seed != 0 {
+		b.tb.Send(m.Chat, fmt.Sprintf("Começando com a semente %d!", seed))
+	} else {
+		b.tb.Send(m.Chat, "Começando!")
+	}
+	b.SendCommitment(g)
 
+	if g.GetCurrentCard().HasSticker() {
+		b.tb.Send(m.Chat, CardSticker(g.GetCurrentCard()))
+	} else {
+		b.tb.Send(m.Chat, g.GetCurrentCard().StringPretty())
+	}
+	b.tb.Send(m.Chat, fmt.Sprintf("Jogador(a) Atual: %s", Mention(g.CurrentPlayer())), tb.ModeMarkdown)
+
+	b.NotifySpectators(g)
+	b.ArmTimer(g)
 	b.Persist()
 }
 
@@ -204,163 +498,124 @@ func (b *Bot) HandleKill(m *tb.Message) {
 
 	if g.State != game.LOBBY {
 		b.SaveGameStats(g)
+		b.SendProof(g)
+		b.Seeds[m.Chat.ID] = g.Seed
 	}
 
-	delete(b.Games, m.Chat.ID)
+	b.EndSpectating(m.Chat.ID, "🏁 O jogo que você estava assistindo foi finalizado.")
+	b.RemoveGame(g)
+}
+
+// HandleUndo handles /undo requests
+// Restores the game to the state before the last move, up to game.UndoHistory moves
+func (b *Bot) HandleUndo(m *tb.Message) {
+	b.logger.Info().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Msg("Undo request received")
+
+	g, ok := b.Games[m.Chat.ID]
+	if !ok {
+		b.logger.Info().Int64("chat_id", m.Chat.ID).Msg("No game running on this chat")
+		b.tb.Send(m.Chat, "Não há nenhum jogo nesse chat! /new para criar um")
+		return
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	if g.GetState() == game.LOBBY || !g.CanUndo() {
+		b.tb.Send(m.Chat, "Não há nenhuma jogada para desfazer!")
+		return
+	}
+
+	if err := g.Undo(); err != nil {
+		b.logger.Error().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Err(err).Send()
+		b.tb.Send(m.Chat, "Erro :(")
+		return
+	}
+
+	// Joins and leaves may have been undone too
 	for k := range b.Players {
-		if b.Players[k] == m.Chat.ID {
+		if b.Players[k] == m.Chat.ID && g.GetPlayer(k) == nil {
 			delete(b.Players, k)
 		}
 	}
-	b.logger.Trace().Int("games_len", len(b.Games)).Send()
 
+	for _, p := range g.Players {
+		b.Players[p.ID] = m.Chat.ID
+	}
+
+	if g.GetState() == game.LOBBY {
+		b.StopTimer(m.Chat.ID)
+		b.tb.Send(m.Chat, fmt.Sprintf("↩️ %s desfez o começo do jogo! /start para começar de novo.\n%s", m.Sender.FirstName, LobbyReport(g)))
+		b.Persist()
+		return
+	}
+
+	b.tb.Send(m.Chat,
+		fmt.Sprintf("↩️ %s desfez a última jogada!\n%s", m.Sender.FirstName, GameInfo(g)),
+		tb.ModeMarkdown,
+	)
+
+	if g.HasPendingCatorce() {
+		// Only people can call catorce, computer players can only be caught
+		markup := b.catchBtnMarkup
+		for _, p := range g.Players {
+			if g.MustCallCatorce(p) && !game.IsAI(p.ID) {
+				markup = b.catorceBtnMarkup
+			}
+		}
+
+		b.tb.Send(m.Chat, "Última carta!", markup)
+	}
+
+	b.NotifySpectators(g)
+	b.ArmTimer(g)
 	b.Persist()
 }
 
 // HandleConfig handles /config requests
+// Without arguments it lists the chat config, "/config <option> <value>" changes an option
 func (b *Bot) HandleConfig(m *tb.Message) {
 	b.logger.Info().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Msg("Config request received")
 
-	// g, ok := b.Games[m.Chat.ID]
-
-	// if ok && g.State != game.LOBBY {
-	// 	b.logger.Info().Int64("chat_id", m.Chat.ID).Msg("There's a game running")
-	// 	b.tb.Send(m.Chat, "Já há um jogo em andamento nesse chat!")
-	// 	return
-	// }
-
-	// if _, found := b.Configs[m.Chat.ID]; !found {
-	// 	b.logger.Info().Int64("chat_id", m.Chat.ID).Msg("No config for chat, creating")
-	// 	b.Configs[m.Chat.ID] = game.DefaultConfig()
-	// }
-
-	// var (
-	// 	config = &tb.ReplyMarkup{}
-	// 	choice = &tb.ReplyMarkup{}
-	// 	sumsub = &tb.ReplyMarkup{}
-
-	// 	btnStackP4 = config.Data("Stack +4", "stack_p4")
-	// 	btnSwap    = config.Data("Swap", "swap")
-	// 	btnP2Amt   = config.Data("Qtd +2", "p2_amt")
-	// 	btnSkipAmt = config.Data("Qtd Skip", "skip_amt")
-	// 	btnDone    = config.Data("Pronto", "done")
-
-	// 	btnTrue  = choice.Data("✔", "true")
-	// 	btnFalse = choice.Data("❌", "false")
-
-	// 	btnPlus   = sumsub.Data("➕", "plus")
-	// 	btnMinus  = sumsub.Data("➖", "minus")
-	// 	btnPMDone = sumsub.Data("Feito", "done")
-	// )
-
-	// config.Inline(
-	// 	config.Row(btnStackP4, btnSwap),
-	// 	config.Row(btnP2Amt, btnSkipAmt),
-	// 	config.Row(btnDone))
-	// choice.Inline(choice.Row(btnTrue, btnFalse))
-	// sumsub.Inline(
-	// 	sumsub.Row(btnPlus, btnMinus),
-	// 	sumsub.Row(btnPMDone))
-
-	// checkSenderAndLockGame := func(f func(c *tb.Callback)) func(c *tb.Callback) {
-	// 	return func(c *tb.Callback) {
-	// 		if c.Sender.ID != m.Sender.ID {
-	// 			return
-	// 		}
-
-	// 		if ok {
-	// 			g.Lock()
-	// 			defer g.Unlock()
-	// 		}
-
-	// 		f(c)
-	// 	}
-	// }
-
-	// handlerTrueFalse := func(label string, val *bool) func(c *tb.Callback) {
-	// 	return func(c *tb.Callback) {
-	// 		if c.Sender.ID != m.Sender.ID {
-	// 			return
-	// 		}
-
-	// 		b.tb.Handle(&btnTrue, checkSenderAndLockGame(func(c *tb.Callback) {
-	// 			*val = true
-	// 			if ok {
-	// 				g.SetConfig(b.Configs[m.Chat.ID])
-	// 			}
-	// 			b.tb.Edit(c.Message, fmt.Sprintf("%s ✔", label))
-	// 			b.Persist()
-	// 		}))
-
-	// 		b.tb.Handle(&btnFalse, checkSenderAndLockGame(func(c *tb.Callback) {
-	// 			*val = false
-	// 			if ok {
-	// 				g.SetConfig(b.Configs[m.Chat.ID])
-	// 			}
-	// 			b.tb.Edit(c.Message, fmt.Sprintf("%s ❌", label))
-	// 			b.Persist()
-	// 		}))
-
-	// 		b.tb.Edit(c.Message, label, choice)
-	// 	}
-	// }
-
-	// handlerNumeric := func(label string, val *int) func(c *tb.Callback) {
-	// 	return func(c *tb.Callback) {
-	// 		if c.Sender.ID != m.Sender.ID {
-	// 			return
-	// 		}
-
-	// 		b.tb.Handle(&btnPlus, checkSenderAndLockGame(func(c *tb.Callback) {
-	// 			*val += 1
-	// 			if ok {
-	// 				g.SetConfig(b.Configs[m.Chat.ID])
-	// 			}
-	// 			b.tb.Edit(c.Message, fmt.Sprintf("%s\nAtual: %d\nPadrão: 2", label, *val), sumsub)
-	// 			b.Persist()
-	// 		}))
-
-	// 		b.tb.Handle(&btnMinus, checkSenderAndLockGame(func(c *tb.Callback) {
-	// 			if *val > 0 {
-	// 				*val -= 1
-	// 			}
-
-	// 			if ok {
-	// 				g.SetConfig(b.Configs[m.Chat.ID])
-	// 			}
-
-	// 			b.tb.Edit(c.Message, fmt.Sprintf("%s\nAtual: %d\nPadrão: 2", label, *val), sumsub)
-	// 			b.Persist()
-	// 		}))
-
-	// 		b.tb.Handle(&btnPMDone, func(c *tb.Callback) {
-	// 			if c.Sender.ID != m.Sender.ID {
-	// 				return
-	// 			}
-	// 			b.tb.Edit(c.Message, fmt.Sprintf("%s\nAtual: %d\nPadrão: 2", label, *val))
-	// 		})
-
-	// 		b.tb.Edit(c.Message, fmt.Sprintf("%s\nAtual: %d\nPadrão: 2", label, *val), sumsub)
-	// 	}
-	// }
-
-	// b.tb.Handle(&btnStackP4, handlerTrueFalse("Deve ser permitido empilhar +4?", &b.Configs[m.Chat.ID].CanStackPlus4))
-	// b.tb.Handle(&btnSwap, handlerTrueFalse("O jogo deve usar a carta de trocar mãos?", &b.Configs[m.Chat.ID].UseSpecialSwap))
-	// b.tb.Handle(&btnP2Amt, handlerNumeric("Quantas cartas +2 devem ter no deck por cor?", &b.Configs[m.Chat.ID].DeckConfig.AmountOfDraw2))
-	// b.tb.Handle(&btnSkipAmt, handlerNumeric("Quantas cartas de skip devem ter no deck por cor?", &b.Configs[m.Chat.ID].DeckConfig.AmountOfDraw2))
-
-	// b.tb.Handle(&btnDone, func(c *tb.Callback) {
-	// 	if c.Sender.ID != m.Sender.ID {
-	// 		return
-	// 	}
-	// 	b.tb.Edit(c.Message, "Feito!")
-	// })
-
-	_, err := b.tb.Send(m.Chat, "NYI")
+	if _, found := b.Configs[m.Chat.ID]; !found {
+		b.logger.Info().Int64("chat_id", m.Chat.ID).Msg("No config for chat, creating")
+		b.Configs[m.Chat.ID] = game.DefaultConfig()
+	}
+
+	cfg := b.Configs[m.Chat.ID]
+	args := strings.Fields(m.Payload)
 
-	if err != nil {
-		b.logger.Error().Err(err).Send()
+	if len(args) == 0 {
+		if _, err := b.tb.Send(m.Chat, ConfigReport(cfg), tb.ModeMarkdown); err != nil {
+			b.logger.Error().Err(err).Send()
+		}
+		return
 	}
+
+	if len(args) != 2 {
+		b.tb.Send(m.Chat, "Uso: /config <opção> <valor>")
+		return
+	}
+
+	g, ok := b.Games[m.Chat.ID]
+	if ok {
+		g.Lock()
+		defer g.Unlock()
+	}
+
+	if err := SetConfigOption(cfg, args[0], args[1]); err != nil {
+		b.logger.Info().Int64("chat_id", m.Chat.ID).Err(err).Msg("Invalid config")
+		b.tb.Send(m.Chat, err.Error())
+		return
+	}
+
+	if ok {
+		g.SetConfig(cfg)
+		b.ArmTimer(g)
+	}
+
+	b.tb.Send(m.Chat, fmt.Sprintf("%s = %s", args[0], configOptions[args[0]].get(cfg)))
+	b.Persist()
 }
 
 // HandleResult handles inline queries choices
@@ -394,7 +649,6 @@ func (b *Bot) HandleResult(c *tb.ChosenInlineResult) {
 	player := g.GetPlayer(c.From.ID)
 
 	if res_id == "draw" {
-		catorce := g.PlayerCatorce
 		if err := g.FireEvent(&game.EvtDrawCard{Player: player}); err != nil {
 			b.logger.Error().Err(err).Int64("chat_id", chat).Send()
 			switch err {
@@ -407,16 +661,22 @@ func (b *Bot) HandleResult(c *tb.ChosenInlineResult) {
 			return
 		}
 
-		// If there was a catorce player and the cards were succesfully drawn
-		// then the catorce'd player received four cards, we need to warn them
-		if catorce != 0 {
-			b.tb.Send(&tb.Chat{ID: chat},
-				fmt.Sprintf(
-					"Oh no! 😱\n%s não chamou CATORCE! a tempo e pegou 4 cartas!",
-					g.GetPlayer(catorce).NameWithMention(),
-				),
-				tb.ModeMarkdown,
-			)
+		if g.Config.DrawUntilPlayable && g.GetState() == game.DREW {
+			b.tb.Send(&tb.Chat{ID: chat}, fmt.Sprintf("%s puxou %d carta(s)", Mention(player), g.LastDrawAmount), tb.ModeMarkdown)
+		}
+	} else if res_id == "challenge" {
+		if err := g.FireEvent(&game.EvtChallenge{Player: player}); err != nil {
+			b.logger.Error().Err(err).Int64("chat_id", chat).Send()
+			switch err {
+			case game.ErrEventNotCovered:
+			case game.ErrWrongPlayer:
+				return
+			case game.ErrCantChallenge:
+				b.tb.Send(&tb.Chat{ID: chat}, "Não dá pra desafiar essa carta!")
+			default:
+				b.tb.Send(&c.From, "Erro :(")
+			}
+			return
 		}
 	} else if res_id == "pass" {
 		if err := g.FireEvent(&game.EvtPass{Player: player}); err != nil {
@@ -445,10 +705,6 @@ func (b *Bot) HandleResult(c *tb.ChosenInlineResult) {
 			}
 			return
 		}
-
-		if g.HasPendingCatorce() {
-			b.tb.Send(&tb.Chat{ID: chat}, "Última carta!", b.catorceBtnMarkup)
-		}
 	} else if strings.HasPrefix(res_id, "player:") {
 		id := strings.Split(res_id, ":")[1]
 		playerID, _ := strconv.Atoi(id)
@@ -480,7 +736,6 @@ func (b *Bot) HandleResult(c *tb.ChosenInlineResult) {
 			return
 		}
 
-		catorce := g.PlayerCatorce
 		if err := g.FireEvent(&game.EvtCardPlayed{Card: card, Player: player}); err != nil {
 			b.logger.Error().Err(err).Int64("chat_id", chat).Send()
 			switch err {
@@ -497,57 +752,82 @@ func (b *Bot) HandleResult(c *tb.ChosenInlineResult) {
 		}
 
 		b.logger.Debug().Str("card", card.String()).Msg("Card played")
-		if g.HasPendingCatorce() {
-			b.tb.Send(&tb.Chat{ID: chat}, "Última carta!", b.catorceBtnMarkup)
-		}
-
-		// If there was a catorce player and the card was succesfully played
-		// then the catorce'd player received four cards, we need to warn them
-		if catorce != 0 {
-			b.tb.Send(&tb.Chat{ID: chat},
-				fmt.Sprintf(
-					"Oh no! 😱\n%s não chamou CATORCE! a tempo e pegou 4 cartas!",
-					g.GetPlayer(catorce).NameWithMention(),
-				),
-				tb.ModeMarkdown,
-			)
-		}
 	}
 
-	// If we returned to lobby, then game is over
-	if g.GetState() == game.LOBBY {
-		b.tb.Send(&tb.Chat{ID: chat},
-			fmt.Sprintf(
-				"Jogo finalizado após %d rounds!!\nVitória de %s",
-				g.Rounds,
-				g.CurrentPlayer().NameWithMention(),
-			),
-			tb.ModeMarkdown,
-		)
+	b.AfterMove(g)
+}
 
-		b.logger.Trace().Msg("game returned to lobby, deleting")
-		b.SaveGameStats(g)
-		delete(b.Games, chat)
-		for k := range b.Players {
-			if b.Players[k] == chat {
-				delete(b.Players, k)
-			}
-		}
-		b.logger.Trace().Int("games_len", len(b.Games)).Send()
+// AfterMove announces the next player after an accepted move, finishing the game if it was won
+// Must be called with the game locked
+func (b *Bot) AfterMove(g *game.Game) {
+	chat := g.Chat
 
-		b.Persist()
+	if b.finishGame(g) {
 		return
 	}
 
-	b.tb.Send(&tb.Chat{ID: chat}, fmt.Sprintf("Próximo(a) jogador(a): %s", g.CurrentPlayer().NameWithMention()), tb.ModeMarkdown)
+	b.tb.Send(&tb.Chat{ID: chat}, fmt.Sprintf("Próximo(a) jogador(a): %s", Mention(g.CurrentPlayer())), tb.ModeMarkdown)
 
 	if g.GetState() == game.CHOOSE_COLOR {
 		b.tb.Send(&tb.Chat{ID: chat}, "Escolha uma cor!")
 	}
 
+	if g.GetState() == game.CHALLENGE {
+		b.tb.Send(&tb.Chat{ID: chat}, "Puxe as cartas ou desafie o +4!")
+	}
+
+	b.NotifySpectators(g)
+	b.ArmTimer(g)
 	b.Persist()
 }
 
+// finishGame ends the game if it was won by the last events, reporting if it did
+func (b *Bot) finishGame(g *game.Game) bool {
+	won, ok := b.won[g.Chat]
+	if !ok {
+		return false
+	}
+
+	b.EndGame(g, won)
+	return true
+}
+
+// UpdateMatch adds a finished game to the chat's match, if the chat plays matches
+// The scoreboard is sent to the chat and the match is over once someone reaches the target
+func (b *Bot) UpdateMatch(g *game.Game) {
+	chat := g.Chat
+
+	if _, ok := b.Matches[chat]; !ok {
+		if g.Config.MatchTarget <= 0 {
+			return
+		}
+
+		b.Matches[chat] = game.NewMatch(g.Config.MatchTarget)
+	}
+
+	m := b.Matches[chat]
+	m.AddGame(g)
+
+	b.tb.Send(&tb.Chat{ID: chat}, MatchReport(m), tb.ModeMarkdown)
+
+	if winners := m.Winners(); len(winners) > 0 {
+		b.logger.Info().Int64("chat_id", chat).Ints("winners", winners).Msg("Match is over")
+
+		names := make([]string, len(winners))
+		for i, id := range winners {
+			names[i] = m.Names[id]
+		}
+
+		b.tb.Send(&tb.Chat{ID: chat}, fmt.Sprintf("🏆 %s venceu a partida!", strings.Join(names, ", ")))
+
+		b.SaveMatchStats(chat, m)
+		delete(b.Matches, chat)
+		return
+	}
+
+	b.tb.Send(&tb.Chat{ID: chat}, "/new para o próximo jogo da partida")
+}
+
 // HandleQuery handles inline queries
 func (b *Bot) HandleQuery(q *tb.Query) {
 	b.logger.Info().Int("user_id", q.From.ID).Msg("New Query received")
@@ -556,7 +836,11 @@ func (b *Bot) HandleQuery(q *tb.Query) {
 	results := Results()
 
 	if chat, ok := b.Players[q.From.ID]; !ok {
-		results.AddNotPlaying()
+		if g, ok := b.spectatedGame(q.From.ID); ok && g.GetState() != game.LOBBY {
+			results.AddSpectatorView(g)
+		} else {
+			results.AddNotPlaying()
+		}
 	} else if g, ok := b.Games[chat]; !ok {
 		results.AddGameNotStarted()
 		b.logger.Info().Int64("chat_id", chat).Msg("No game running on this chat")
@@ -570,15 +854,27 @@ func (b *Bot) HandleQuery(q *tb.Query) {
 
 		if player.ID != g.CurrentPlayer().ID {
 			for _, c := range player.Hand {
-				results.AddCard(g, c, false)
+				results.AddCard(g, c, g.CanJumpIn(player, c))
 			}
 		} else if g.GetState() == game.CHOOSE_CARD || g.GetState() == game.DREW {
 			if g.GetState() == game.CHOOSE_CARD {
-				results.AddDraw(g.DrawCounter())
+				if g.Config.DrawUntilPlayable && g.DrawCounter() == 0 {
+					results.AddDrawUntilPlayable()
+				} else {
+					results.AddDraw(g.DrawCounter())
+				}
 			} else if g.GetState() == game.DREW {
 				results.AddPass()
 			}
 
+			for _, c := range player.Hand {
+				can_play := c.CanPlayOnTop(g.GetCurrentCard(), g.DrawCounter() > 0, g.Config.StackConfig)
+				results.AddCard(g, c, can_play)
+			}
+		} else if g.GetState() == game.CHALLENGE {
+			results.AddDraw(g.DrawCounter())
+			results.AddChallenge()
+
 			for _, c := range player.Hand {
 				can_play := c.CanPlayOnTop(g.GetCurrentCard(), g.DrawCounter() > 0, g.Config.StackConfig)
 				results.AddCard(g, c, can_play)
@@ -635,6 +931,76 @@ func (b *Bot) HandleCatorce(c *tb.Callback) {
 	b.Persist()
 }
 
+// HandleCatch handles catch button clicks, penalizing whoever didn't call catorce yet
+func (b *Bot) HandleCatch(c *tb.Callback) {
+	m := c.Message
+	b.logger.Info().Int("user_id", c.Sender.ID).Int64("chat", m.Chat.ID).Msg("New Handle Catch")
+
+	if _, ok := b.Games[m.Chat.ID]; !ok {
+		b.logger.Info().Int64("chat_id", m.Chat.ID).Msg("No game running on this chat")
+		return
+	}
+
+	g := b.Games[m.Chat.ID]
+	g.Lock()
+	defer g.Unlock()
+
+	player := g.GetPlayer(c.Sender.ID)
+
+	evt := &game.EvtCatchCatorce{Player: player}
+	if err := g.FireEvent(evt); err != nil {
+		b.logger.Error().Err(err).Int64("chat_id", m.Chat.ID).Send()
+		switch err {
+		case game.ErrWrongPlayer:
+			b.tb.Respond(c, &tb.CallbackResponse{Text: "Você não pode pegar ninguém aqui!"})
+		case game.ErrCatchTooSoon:
+			b.tb.Respond(c, &tb.CallbackResponse{Text: "Calma! Ainda dá tempo de chamar CATORCE!"})
+		default:
+			b.tb.Respond(c, &tb.CallbackResponse{Text: "Não tem ninguém pra pegar!"})
+		}
+		return
+	}
+
+	b.tb.Respond(c, &tb.CallbackResponse{Text: "Pegou!"})
+	b.tb.Edit(m, fmt.Sprintf("Última carta!\n%s foi pego(a) por %s!", game.PlayerNames(evt.Caught), player.Name))
+	b.Persist()
+}
+
+// HandleMatch handles /match requests
+// Can only be used in groups
+func (b *Bot) HandleMatch(m *tb.Message) {
+	b.logger.Info().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Msg("New match request received")
+
+	match, ok := b.Matches[m.Chat.ID]
+	if !ok {
+		b.tb.Send(m.Chat, "Não há nenhuma partida em andamento nesse chat! /config partida <pontos> para jogar partidas")
+		return
+	}
+
+	if _, err := b.tb.Send(m.Chat, MatchReport(match), tb.ModeMarkdown); err != nil {
+		b.logger.Error().Err(err).Int64("chat_id", m.Chat.ID).Send()
+	}
+}
+
+// HandleSeed handles /seed requests
+// Shows the seed of the last finished game in the chat, a running game's seed is never shown
+func (b *Bot) HandleSeed(m *tb.Message) {
+	b.logger.Info().Int64("chat_id", m.Chat.ID).Int("user_id", m.Sender.ID).Msg("New seed request received")
+
+	if g, ok := b.Games[m.Chat.ID]; ok && g.GetState() != game.LOBBY {
+		b.tb.Send(m.Chat, "A semente só pode ser vista depois que o jogo acabar!")
+		return
+	}
+
+	seed, ok := b.Seeds[m.Chat.ID]
+	if !ok {
+		b.tb.Send(m.Chat, "Nenhum jogo terminou nesse chat ainda!")
+		return
+	}
+
+	b.tb.Send(m.Chat, fmt.Sprintf("Semente do último jogo: `%d`\n/start %d para repetir o embaralhamento", seed, seed), tb.ModeMarkdown)
+}
+
 // HandleStats handles /stats requests
 // Can only be used in groups
 func (b *Bot) HandleStats(m *tb.Message) {
@@ -666,6 +1032,22 @@ func (b *Bot) HandleStats(m *tb.Message) {
 	if err != nil {
 		b.logger.Error().Err(err).Int64("chat_id", m.Chat.ID).Send()
 	}
+
+	if teams := cs.TeamRanking(); teams != "" {
+		_, err = b.tb.Send(m.Chat, teams, tb.ModeMarkdown)
+
+		if err != nil {
+			b.logger.Error().Err(err).Int64("chat_id", m.Chat.ID).Send()
+		}
+	}
+
+	if len(cs.Matches) > 0 {
+		_, err = b.tb.Send(m.Chat, cs.MatchRanking(), tb.ModeMarkdown)
+
+		if err != nil {
+			b.logger.Error().Err(err).Int64("chat_id", m.Chat.ID).Send()
+		}
+	}
 }
 
 // HandleSelfStats handles /statsself requests
@@ -0,0 +1,68 @@
+package bot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/d-nery/catorce/pkg/game"
+)
+
+// JournalDir is where game journals are kept, one file per game
+const JournalDir = "data/journal"
+
+// OpenJournal starts the journal of a new game
+func (b *Bot) OpenJournal(g *game.Game) {
+	path := filepath.Join(JournalDir, fmt.Sprintf("%d-%d.jsonl", g.Chat, time.Now().UnixNano()))
+
+	if err := g.OpenJournal(path); err != nil {
+		b.logger.Error().Err(err).Int64("chat_id", g.Chat).Msg("Failed to open journal")
+	}
+}
+
+// RecoverFromJournals rebuilds the running games from their journals
+// Used when the persistance file can't be loaded, only the latest unfinished journal of each chat is replayed
+func (b *Bot) RecoverFromJournals() {
+	paths, err := filepath.Glob(filepath.Join(JournalDir, "*.jsonl"))
+	if err != nil {
+		b.logger.Error().Err(err).Msg("Failed to list journals")
+		return
+	}
+
+	// File names end with the creation time, so later games replace earlier ones in the same chat
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			b.logger.Error().Err(err).Str("path", path).Msg("Failed to open journal")
+			continue
+		}
+
+		g, ended, err := game.Replay(f, b.logger)
+		f.Close()
+
+		if err != nil {
+			b.logger.Error().Err(err).Str("path", path).Msg("Failed to replay journal")
+			continue
+		}
+
+		if ended {
+			delete(b.Games, g.Chat)
+			continue
+		}
+
+		b.logger.Info().Int64("chat_id", g.Chat).Str("path", path).Msg("Game recovered from journal")
+		b.Games[g.Chat] = g
+		b.Configs[g.Chat] = g.Config
+		g.JournalPath = path
+	}
+
+	for chat, g := range b.Games {
+		for _, p := range g.Players {
+			b.Players[p.ID] = chat
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package bot
+
+import (
+	"fmt"
+
+	"github.com/d-nery/catorce/pkg/deck"
+	"github.com/d-nery/catorce/pkg/game"
+	tb "gopkg.in/tucnak/telebot.v2"
+)
+
+// Watch makes the bot react to the outcomes of the game's events
+func (b *Bot) Watch(g *game.Game) {
+	g.AddListener(b.HandleOutcome)
+}
+
+// HandleOutcome announces what happened in a game
+// Called by the game while firing events, so the game is already locked
+func (b *Bot) HandleOutcome(g *game.Game, o game.Outcome) {
+	chat := &tb.Chat{ID: g.Chat}
+
+	switch o := o.(type) {
+	case game.CardPlayed:
+		if o.JumpedIn {
+			b.tb.Send(chat, fmt.Sprintf("⚡ %s cortou a vez!", Mention(o.Player)), tb.ModeMarkdown)
+		}
+
+	case game.PenaltyApplied:
+		if o.CaughtBy != nil {
+			b.tb.Send(chat,
+				fmt.Sprintf("🫵 %s pegou %s sem chamar CATORCE! Multa de %d cartas!", Mention(o.CaughtBy), Mention(o.Player), o.Amount),
+				tb.ModeMarkdown,
+			)
+			break
+		}
+
+		b.tb.Send(chat,
+			fmt.Sprintf("Oh no! 😱\n%s não chamou CATORCE! a tempo e pegou %d cartas!", Mention(o.Player), o.Amount),
+			tb.ModeMarkdown,
+		)
+
+	case game.ChallengeResolved:
+		if o.Guilty {
+			b.tb.Send(chat,
+				fmt.Sprintf("Blefe! 🤥 %s tinha a cor anterior e puxou %d cartas", Mention(o.Bluffer), o.Amount),
+				tb.ModeMarkdown,
+			)
+		} else {
+			b.tb.Send(chat,
+				fmt.Sprintf("%s jogou limpo! %s puxou %d cartas", Mention(o.Bluffer), Mention(o.Challenger), o.Amount),
+				tb.ModeMarkdown,
+			)
+		}
+
+	case game.RouletteSpun:
+		b.tb.Send(chat,
+			fmt.Sprintf("🎰 %s puxou %d cartas até tirar %s e perdeu a vez!", Mention(o.Player), o.Amount, deck.COLOR_ICONS[o.Color]),
+			tb.ModeMarkdown,
+		)
+
+	case game.CatorcePending:
+		// A computer can't press CATORCE!, but the others can still catch it if it forgets
+		if o.Player.IsAI() {
+			b.tb.Send(chat, fmt.Sprintf("Última carta de %s!", Mention(o.Player)), b.catchBtnMarkup, tb.ModeMarkdown)
+		} else {
+			b.tb.Send(chat, "Última carta!", b.catorceBtnMarkup)
+		}
+
+	case game.PlayerLeft:
+		b.SaveAbandonStats(g.Chat, o.Player, o.Points)
+		b.tb.Send(chat, fmt.Sprintf("%s abandonou o jogo! Suas cartas voltaram para o baralho.", Mention(o.Player)), tb.ModeMarkdown)
+
+	case game.GameWon:
+		// The move itself is still being announced, the game is finished by AfterMove
+		b.won[g.Chat] = o
+	}
+}
+
+// EndGame announces the winner of a game, saves its results and removes it
+// Must be called with the game locked
+func (b *Bot) EndGame(g *game.Game, won game.GameWon) {
+	chat := g.Chat
+
+	b.tb.Send(&tb.Chat{ID: chat},
+		fmt.Sprintf(
+			"Jogo finalizado após %d rounds!!\nVitória de %s",
+			g.Rounds,
+			Mention(won.Player),
+		),
+		tb.ModeMarkdown,
+	)
+
+	if won.Team != game.NO_TEAM {
+		b.tb.Send(&tb.Chat{ID: chat},
+			fmt.Sprintf(
+				"🤝 Vitória do %s!\nPontos: %s %d × %d %s",
+				TeamName(won.Team),
+				TeamName(game.TEAM_A), g.TeamPoints(game.TEAM_A),
+				g.TeamPoints(game.TEAM_B), TeamName(game.TEAM_B),
+			),
+		)
+	}
+
+	b.EndSpectating(chat, fmt.Sprintf("🏁 Jogo finalizado! Vitória de %s", Mention(won.Player)))
+
+	b.logger.Trace().Msg("game won, deleting")
+	b.SaveGameStats(g)
+	b.UpdateMatch(g)
+	b.SendProof(g)
+	b.Seeds[chat] = g.Seed
+	b.RemoveGame(g)
+}
+
+// RemoveGame closes a game's journal and forgets it and its players
+func (b *Bot) RemoveGame(g *game.Game) {
+	chat := g.Chat
+
+	g.CloseJournal()
+	b.StopTimer(chat)
+	delete(b.Games, chat)
+	delete(b.won, chat)
+	for k := range b.Players {
+		if b.Players[k] == chat {
+			delete(b.Players, k)
+		}
+	}
+	b.logger.Trace().Int("games_len", len(b.Games)).Send()
+
+	b.Persist()
+}
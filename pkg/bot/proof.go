@@ -0,0 +1,43 @@
+package bot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/d-nery/catorce/pkg/game"
+	tb "gopkg.in/tucnak/telebot.v2"
+)
+
+// SendCommitment posts the commitment of the game's shuffled deck, so the deal can be verified later
+func (b *Bot) SendCommitment(g *game.Game) {
+	b.tb.Send(&tb.Chat{ID: g.Chat},
+		fmt.Sprintf("🔒 Compromisso do baralho: `%s`\nAo final do jogo eu revelo o baralho para quem quiser conferir.", g.Deck.Commitment),
+		tb.ModeMarkdown,
+	)
+}
+
+// SendProof reveals the deck of a finished game as a JSON document
+// Anyone can check it against the commitment with `catorce verify <file>`
+func (b *Bot) SendProof(g *game.Game) {
+	if g.Deck == nil || g.Deck.Commitment == "" {
+		return
+	}
+
+	body, err := json.MarshalIndent(g.Deck.Proof(), "", "  ")
+	if err != nil {
+		b.logger.Error().Err(err).Int64("chat_id", g.Chat).Msg("Failed to generate proof")
+		return
+	}
+
+	doc := &tb.Document{
+		File:     tb.FromReader(bytes.NewReader(body)),
+		FileName: fmt.Sprintf("baralho-%s.json", g.Deck.Commitment[:8]),
+		MIME:     "application/json",
+		Caption:  "🔓 Baralho revelado! Confira com `catorce verify <arquivo>`",
+	}
+
+	if _, err := b.tb.Send(&tb.Chat{ID: g.Chat}, doc, tb.ModeMarkdown); err != nil {
+		b.logger.Error().Err(err).Int64("chat_id", g.Chat).Msg("Failed to send proof")
+	}
+}
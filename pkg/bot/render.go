@@ -0,0 +1,96 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/d-nery/catorce/pkg/deck"
+	"github.com/d-nery/catorce/pkg/game"
+	tb "gopkg.in/tucnak/telebot.v2"
+)
+
+// Mention returns the Markdown formatted player name, mentioning them on telegram
+func Mention(p *game.Player) string {
+	if p.IsAI() {
+		return fmt.Sprintf("🤖 *%s*", p.Name)
+	}
+
+	if p.Username == "" {
+		return fmt.Sprintf("[%s](tg://user?id=%d)", p.Name, p.ID)
+	}
+
+	return fmt.Sprintf("*%s* (@%s)", p.Name, p.Username)
+}
+
+// CardSticker returns the sticker of card c
+func CardSticker(c *deck.Card) *tb.Sticker {
+	return &tb.Sticker{
+		File: tb.File{FileID: c.Sticker()},
+	}
+}
+
+// GameInfo generates a Markdown formatted string with the public game info
+func GameInfo(g *game.Game) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "Jogador atual: %s \\[%d]\n", Mention(g.CurrentPlayer()), len(g.CurrentPlayer().Hand))
+	fmt.Fprintf(&out, "Última carta: %s\n", g.GetCurrentCard().StringPretty())
+	fmt.Fprint(&out, "Próximos Jogadores:\n")
+
+	for _, p := range g.PlayerList() {
+		if p == g.CurrentPlayer() {
+			continue
+		}
+
+		fmt.Fprintf(&out, " • %s \\[%d]\n", p.Name, len(p.Hand))
+	}
+
+	fmt.Fprintf(&out, "Cartas na pilha: %d\n", g.Deck.Available())
+
+	return out.String()
+}
+
+// SpectatorInfo generates the public game info plus the recent plays, it never shows any hand
+func SpectatorInfo(g *game.Game) string {
+	var out strings.Builder
+	out.WriteString(GameInfo(g))
+
+	if len(g.RecentPlays) > 0 {
+		out.WriteString("\nJogadas recentes:\n")
+
+		for _, p := range g.RecentPlays {
+			fmt.Fprintf(&out, " • %s\n", PlayText(p))
+		}
+	}
+
+	return out.String()
+}
+
+// PlayText describes a play
+func PlayText(p game.Play) string {
+	switch p.Event {
+	case "EvtCardPlayed":
+		return fmt.Sprintf("%s jogou %s", p.Player, p.Card.StringPretty())
+	case "EvtColorChosen":
+		return fmt.Sprintf("%s escolheu %s", p.Player, deck.COLOR_ICONS[p.Color])
+	case "EvtPlayerSwapChosen":
+		return fmt.Sprintf("%s trocou de mão com %s", p.Player, p.Target)
+	case "EvtDrawCard":
+		return fmt.Sprintf("%s puxou %d carta(s)", p.Player, p.Drawn)
+	case "EvtChallenge":
+		return fmt.Sprintf("%s desafiou o +4", p.Player)
+	case "EvtPass":
+		return fmt.Sprintf("%s passou a vez", p.Player)
+	case "EvtCatorce":
+		return fmt.Sprintf("%s chamou CATORCE!", p.Player)
+	case "EvtCatchCatorce":
+		return fmt.Sprintf("%s pegou %s sem chamar CATORCE!", p.Player, p.Target)
+	case game.JournalTimeout:
+		if p.Drawn > 0 {
+			return fmt.Sprintf("⏰ %s demorou demais e puxou %d carta(s)", p.Player, p.Drawn)
+		}
+
+		return fmt.Sprintf("⏰ %s demorou demais", p.Player)
+	}
+
+	return fmt.Sprintf("%s: %s", p.Player, p.Event)
+}
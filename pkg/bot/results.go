@@ -64,7 +64,24 @@ func (rb *ResultBuilder) AddGameInfo(g *game.Game) *ResultBuilder {
 	}
 
 	res.SetContent(&tb.InputTextMessageContent{
-		Text:      g.GameInfo(),
+		Text:      GameInfo(g),
+		ParseMode: tb.ModeMarkdown,
+	})
+
+	rb.results = append(rb.results, res)
+
+	return rb
+}
+
+// AddSpectatorView adds an ArticleResult with the public game view, for spectators
+func (rb *ResultBuilder) AddSpectatorView(g *game.Game) *ResultBuilder {
+	res := &tb.ArticleResult{}
+	res.ID = "spectate"
+	res.Title = "Assistindo o jogo"
+	res.Description = fmt.Sprintf("Vez de %s, %d cartas na mão", g.CurrentPlayer().Name, len(g.CurrentPlayer().Hand))
+
+	res.SetContent(&tb.InputTextMessageContent{
+		Text:      SpectatorInfo(g),
 		ParseMode: tb.ModeMarkdown,
 	})
 
@@ -90,6 +107,33 @@ func (rb *ResultBuilder) AddDraw(amount int) *ResultBuilder {
 	return rb
 }
 
+// AddDrawUntilPlayable adds an StickerResult with the Draw action, for when the player draws until they can play
+func (rb *ResultBuilder) AddDrawUntilPlayable() *ResultBuilder {
+	res := &tb.StickerResult{}
+	res.Cache = DRAW_STICKER
+	res.ID = "draw"
+	res.SetContent(&tb.InputTextMessageContent{
+		Text: "Puxando até conseguir jogar",
+	})
+
+	rb.results = append(rb.results, res)
+	return rb
+}
+
+// AddChallenge adds an ArticleResult with the Challenge action
+func (rb *ResultBuilder) AddChallenge() *ResultBuilder {
+	res := &tb.ArticleResult{}
+	res.ID = "challenge"
+	res.Title = "Desafiar!"
+	res.Description = "Se quem jogou o +4 tinha a cor anterior, ele puxa as cartas. Senão, você puxa mais 2"
+	res.SetContent(&tb.InputTextMessageContent{
+		Text: "Desafio! 🧐",
+	})
+
+	rb.results = append(rb.results, res)
+	return rb
+}
+
 // AddPass adds an StickerResult with the Pass action
 func (rb *ResultBuilder) AddPass() *ResultBuilder {
 	res := &tb.StickerResult{}
@@ -104,7 +148,12 @@ func (rb *ResultBuilder) AddPass() *ResultBuilder {
 }
 
 // AddCard adds an StickerResult with a card
+// Cards without a sticker are added as an ArticleResult instead
 func (rb *ResultBuilder) AddCard(g *game.Game, c *deck.Card, can_play bool) *ResultBuilder {
+	if !c.HasSticker() {
+		return rb.addCardArticle(g, c, can_play)
+	}
+
 	res := &tb.StickerResult{}
 
 	if can_play {
@@ -114,7 +163,31 @@ func (rb *ResultBuilder) AddCard(g *game.Game, c *deck.Card, can_play bool) *Res
 		res.Cache = c.StickerNotAvailable()
 		res.ID = fmt.Sprintf("cantplay:%s", c.UID())
 		res.SetContent(&tb.InputTextMessageContent{
-			Text:      g.GameInfo(),
+			Text:      GameInfo(g),
+			ParseMode: tb.ModeMarkdown,
+		})
+	}
+
+	rb.results = append(rb.results, res)
+
+	return rb
+}
+
+// addCardArticle adds an ArticleResult with a card's textual representation
+func (rb *ResultBuilder) addCardArticle(g *game.Game, c *deck.Card, can_play bool) *ResultBuilder {
+	res := &tb.ArticleResult{}
+	res.Title = c.StringPretty()
+
+	if can_play {
+		res.ID = c.UID()
+		res.SetContent(&tb.InputTextMessageContent{
+			Text: c.StringPretty(),
+		})
+	} else {
+		res.ID = fmt.Sprintf("cantplay:%s", c.UID())
+		res.Description = "Não pode ser jogada agora"
+		res.SetContent(&tb.InputTextMessageContent{
+			Text:      GameInfo(g),
 			ParseMode: tb.ModeMarkdown,
 		})
 	}
@@ -143,7 +216,7 @@ func (rb *ResultBuilder) AddCurrentPlayerHand(g *game.Game) *ResultBuilder {
 
 	res.Description = desc
 	res.SetContent(&tb.InputTextMessageContent{
-		Text:      g.GameInfo(),
+		Text:      GameInfo(g),
 		ParseMode: tb.ModeMarkdown,
 	})
 
@@ -185,7 +258,7 @@ func (rb *ResultBuilder) AddPlayerList(g *game.Game) *ResultBuilder {
 		res.Title = p.Name
 		res.Description = fmt.Sprintf("%d cartas", len(p.Hand))
 		res.SetContent(&tb.InputTextMessageContent{
-			Text:      fmt.Sprint("Escolheu ", p.NameWithMention()),
+			Text:      fmt.Sprint("Escolheu ", Mention(p)),
 			ParseMode: tb.ModeMarkdown,
 		})
 
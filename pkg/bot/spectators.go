@@ -0,0 +1,43 @@
+package bot
+
+import (
+	"github.com/d-nery/catorce/pkg/game"
+	tb "gopkg.in/tucnak/telebot.v2"
+)
+
+// spectatedGame returns the game the user is spectating, if any
+func (b *Bot) spectatedGame(user int) (*game.Game, bool) {
+	chat, ok := b.Spectators[user]
+	if !ok {
+		return nil, false
+	}
+
+	g, ok := b.Games[chat]
+	return g, ok
+}
+
+// NotifySpectators sends the public game view to everyone spectating the game
+// Must be called with the game locked
+func (b *Bot) NotifySpectators(g *game.Game) {
+	for user, chat := range b.Spectators {
+		if chat != g.Chat {
+			continue
+		}
+
+		if _, err := b.tb.Send(&tb.User{ID: user}, SpectatorInfo(g), tb.ModeMarkdown); err != nil {
+			b.logger.Error().Err(err).Int64("chat_id", chat).Int("user_id", user).Msg("Failed to notify spectator")
+		}
+	}
+}
+
+// EndSpectating sends msg to everyone spectating the chat and stops their spectating
+func (b *Bot) EndSpectating(chat int64, msg string) {
+	for user, c := range b.Spectators {
+		if c != chat {
+			continue
+		}
+
+		b.tb.Send(&tb.User{ID: user}, msg, tb.ModeMarkdown)
+		delete(b.Spectators, user)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -18,6 +19,7 @@ type OverallStats map[int64]*ChatStats
 type ChatStats struct {
 	Group   GroupStats
 	Players map[int]*PlayerStats
+	Matches map[int]*MatchStats
 }
 
 // GroupStats holds group stats for a specific chat
@@ -25,6 +27,7 @@ type GroupStats struct {
 	GamesPlayed         int
 	P2Sequence          int
 	P4Played            int
+	LargestDraw         int
 	RoundsPlayed        int
 	LargestResponseTime time.Duration
 }
@@ -34,13 +37,29 @@ type PlayerStats struct {
 	Name            string
 	GamesWon        int
 	GamesPlayed     int
+	GamesAbandoned  int
+	TeamGamesPlayed int
+	TeamGamesWon    int
 	Points          int
 	CatorcesCalled  int
 	CatorcesMissed  int
+	Catches         int
 	CardsPlayed     int
+	CardsDrawn      int
+	TimeOuts        int
+	ChallengesWon   int
+	ChallengesLost  int
 	AvgResponseTime time.Duration
 }
 
+// MatchStats holds player match results for a specific chat
+type MatchStats struct {
+	Name          string
+	MatchesPlayed int
+	MatchesWon    int
+	Points        int
+}
+
 // ReadStatsFromFile loads OverallStats from a .json file
 func ReadStatsFromFile(file string) (OverallStats, error) {
 	s := OverallStats{}
@@ -89,14 +108,19 @@ func (gs *GroupStats) AddGameStats(g *game.Game) {
 		gs.P2Sequence = g.P2Sequence
 	}
 
+	if g.LargestDraw > gs.LargestDraw {
+		gs.LargestDraw = g.LargestDraw
+	}
+
 	gs.P4Played += g.P4Played
 	gs.RoundsPlayed += g.Rounds
 }
 
 // AddPlayerStats adds stats from the player to the PlayerStats
-func (ps *PlayerStats) AddPlayerStats(p *game.Player) {
+// points are the points left on the player's hand
+func (ps *PlayerStats) AddPlayerStats(p *game.Player, won bool, points int) {
 	ps.GamesPlayed += 1
-	if len(p.Hand) == 0 {
+	if won {
 		ps.GamesWon += 1
 	}
 
@@ -108,23 +132,103 @@ func (ps *PlayerStats) AddPlayerStats(p *game.Player) {
 	}
 
 	ps.CardsPlayed += p.CardsPlayed
-	ps.Points += p.CurrentHandPoints()
+	ps.CardsDrawn += p.CardsDrawn
+	ps.Points += points
 	ps.CatorcesCalled += p.CatorcesCalled
 	ps.CatorcesMissed += p.CatorcesMissed
+	ps.Catches += p.Catches
+	ps.TimeOuts += p.TimeOuts
+	ps.ChallengesWon += p.ChallengesWon
+	ps.ChallengesLost += p.ChallengesLost
+}
+
+// chatStats returns the stats of the chat, creating them if the chat has none yet
+// Games can finish without /new in this run, e.g. if they were recovered from journals
+func (b *Bot) chatStats(chat int64) *ChatStats {
+	if _, ok := b.stats[chat]; !ok {
+		b.logger.Info().Int64("chat_id", chat).Msg("No stats for current chat, creating")
+		b.stats[chat] = &ChatStats{
+			Group:   GroupStats{},
+			Players: make(map[int]*PlayerStats),
+		}
+	}
+
+	stats := b.stats[chat]
+	if stats.Players == nil {
+		stats.Players = make(map[int]*PlayerStats)
+	}
+
+	return stats
 }
 
 // SaveGameStats saves game and player's stats to the Bot's overall stats
 // Should only be called after the game is finished
 func (b *Bot) SaveGameStats(g *game.Game) {
-	stats := b.stats[g.Chat]
+	stats := b.chatStats(g.Chat)
 	stats.Group.AddGameStats(g)
 
 	for _, p := range g.PlayerList() {
+		// Computer-controlled players don't have stats
+		if p.IsAI() {
+			continue
+		}
+
 		if _, ok := stats.Players[p.ID]; !ok {
 			stats.Players[p.ID] = &PlayerStats{Name: p.Name}
 		}
 
-		stats.Players[p.ID].AddPlayerStats(p)
+		ps := stats.Players[p.ID]
+		ps.AddPlayerStats(p, p.ID == g.Winner, p.CurrentHandPoints(g.Config.Scores))
+
+		if p.Team != game.NO_TEAM {
+			ps.TeamGamesPlayed += 1
+
+			if p.Team == g.WinningTeam() {
+				ps.TeamGamesWon += 1
+			}
+		}
+	}
+}
+
+// SaveAbandonStats saves the stats of a player that left the game before it was over
+// points are the points of the hand the player had when leaving, as it's returned to the deck
+func (b *Bot) SaveAbandonStats(chat int64, p *game.Player, points int) {
+	stats := b.chatStats(chat)
+
+	if _, ok := stats.Players[p.ID]; !ok {
+		stats.Players[p.ID] = &PlayerStats{Name: p.Name}
+	}
+
+	ps := stats.Players[p.ID]
+	ps.AddPlayerStats(p, false, points)
+	ps.GamesAbandoned += 1
+}
+
+// SaveMatchStats saves the results of a finished match to the Bot's overall stats
+func (b *Bot) SaveMatchStats(chat int64, m *game.Match) {
+	stats := b.chatStats(chat)
+
+	if stats.Matches == nil {
+		stats.Matches = make(map[int]*MatchStats)
+	}
+
+	winners := m.Winners()
+	for id, points := range m.Scores {
+		if game.IsAI(id) {
+			continue
+		}
+
+		if _, ok := stats.Matches[id]; !ok {
+			stats.Matches[id] = &MatchStats{Name: m.Names[id]}
+		}
+
+		ms := stats.Matches[id]
+		ms.MatchesPlayed += 1
+		ms.Points += points
+
+		if slices.Contains(winners, id) {
+			ms.MatchesWon += 1
+		}
 	}
 }
 
@@ -136,7 +240,8 @@ func (gs *GroupStats) Report() string {
 	fmt.Fprintf(&out, "Total de Jogos: %d\n", gs.GamesPlayed)
 	fmt.Fprintf(&out, "Total de Rounds: %d\n\n", gs.RoundsPlayed)
 	fmt.Fprintf(&out, "Maior sequência de +2: +%d\n", gs.P2Sequence)
-	fmt.Fprintf(&out, "Quantidade de +4 jogados: %d\n\n", gs.P4Played)
+	fmt.Fprintf(&out, "Quantidade de +4 jogados: %d\n", gs.P4Played)
+	fmt.Fprintf(&out, "Mais cartas puxadas de uma vez: %d\n\n", gs.LargestDraw)
 	fmt.Fprintf(&out, "Maior tempo de resposta: %s", gs.LargestResponseTime.Round(time.Minute))
 
 	return out.String()
@@ -159,6 +264,58 @@ func (cs *ChatStats) Ranking() string {
 	return "```\n" + t.Render() + "\n```"
 }
 
+// TeamRanking generates a Markdown formatted table with the team game record of each player
+// Returns an empty string if no team games were played in the chat
+func (cs *ChatStats) TeamRanking() string {
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"Nome", "Vitórias", "Jogos em equipe"})
+
+	for _, ps := range cs.Players {
+		if ps.TeamGamesPlayed > 0 {
+			t.AppendRow(table.Row{ps.Name, ps.TeamGamesWon, ps.TeamGamesPlayed})
+		}
+	}
+
+	if t.Length() == 0 {
+		return ""
+	}
+
+	t.SortBy([]table.SortBy{
+		{Name: "Vitórias", Mode: table.DscNumeric},
+	})
+
+	return "```\n" + t.Render() + "\n```"
+}
+
+// MatchRanking generates a Markdown formatted table with the chat match ranking
+func (cs *ChatStats) MatchRanking() string {
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"Nome", "Vitórias", "Partidas", "Pontos"})
+
+	for _, ms := range cs.Matches {
+		t.AppendRow(table.Row{ms.Name, ms.MatchesWon, ms.MatchesPlayed, ms.Points})
+	}
+
+	t.SortBy([]table.SortBy{
+		{Name: "Vitórias", Mode: table.DscNumeric},
+	})
+
+	return "```\n" + t.Render() + "\n```"
+}
+
+// MatchReport generates a Markdown formatted string with the match scoreboard
+func MatchReport(m *game.Match) string {
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "*Placar da partida* (%d jogos, até %d pontos)\n\n", m.Games, m.Target)
+
+	for _, id := range m.Standings() {
+		fmt.Fprintf(&out, " • %s: %d\n", m.Names[id], m.Scores[id])
+	}
+
+	return out.String()
+}
+
 // Report generates a Markdown formatted string with PlayerStats report
 func (ps *PlayerStats) Report() string {
 	var out strings.Builder
@@ -166,9 +323,15 @@ func (ps *PlayerStats) Report() string {
 	fmt.Fprintf(&out, "*Suas Estatísticas*\n\n")
 	fmt.Fprintf(&out, "Total de jogos: %d\n", ps.GamesPlayed)
 	fmt.Fprintf(&out, "Total de jogos vencidos: %d\n", ps.GamesWon)
+	fmt.Fprintf(&out, "Total de jogos abandonados: %d\n", ps.GamesAbandoned)
+	fmt.Fprintf(&out, "Jogos em equipe vencidos: %d/%d\n", ps.TeamGamesWon, ps.TeamGamesPlayed)
 	fmt.Fprintf(&out, "Total de pontos (menos é melhor): %d\n", ps.Points)
 	fmt.Fprintf(&out, "Total de cartas jogadas: %d\n", ps.CardsPlayed)
-	fmt.Fprintf(&out, "Catorces: %d/%d\n\n", ps.CatorcesCalled, ps.CatorcesCalled+ps.CatorcesMissed)
+	fmt.Fprintf(&out, "Total de cartas puxadas: %d\n", ps.CardsDrawn)
+	fmt.Fprintf(&out, "Catorces: %d/%d\n", ps.CatorcesCalled, ps.CatorcesCalled+ps.CatorcesMissed)
+	fmt.Fprintf(&out, "Jogadores pegos sem catorce: %d\n", ps.Catches)
+	fmt.Fprintf(&out, "Vezes que o tempo esgotou: %d\n", ps.TimeOuts)
+	fmt.Fprintf(&out, "Desafios de +4: %d ganhos, %d perdidos\n\n", ps.ChallengesWon, ps.ChallengesLost)
 	fmt.Fprintf(&out, "Tempo médio de resposta: %s", ps.AvgResponseTime.Round(time.Second))
 
 	return out.String()
@@ -0,0 +1,154 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/d-nery/catorce/pkg/ai"
+	"github.com/d-nery/catorce/pkg/deck"
+	"github.com/d-nery/catorce/pkg/game"
+	tb "gopkg.in/tucnak/telebot.v2"
+)
+
+// AIDelay is how long computer-controlled players take to move, so people can follow the game
+const AIDelay = 2 * time.Second
+
+// ArmTimer (re)starts the turn timeout for the game's current turn
+// Any previous timer for the chat is stopped, nothing is armed if the game has no deadline
+// Computer-controlled players get a short timer for their move instead
+func (b *Bot) ArmTimer(g *game.Game) {
+	b.StopTimer(g.Chat)
+
+	if g.GetState() != game.LOBBY && g.CurrentPlayer().IsAI() {
+		b.armAITimer(g)
+		return
+	}
+
+	deadline := g.TurnDeadline()
+	if deadline.IsZero() {
+		return
+	}
+
+	chat, started := g.Chat, g.TurnStarted
+	b.logger.Trace().Int64("chat_id", chat).Time("deadline", deadline).Msg("Arming turn timer")
+
+	b.timersMx.Lock()
+	b.timers[chat] = time.AfterFunc(time.Until(deadline), func() {
+		b.HandleTimeout(chat, started)
+	})
+	b.timersMx.Unlock()
+}
+
+// armAITimer schedules the move of the computer-controlled current player
+func (b *Bot) armAITimer(g *game.Game) {
+	chat := g.Chat
+	b.logger.Trace().Int64("chat_id", chat).Msg("Arming AI timer")
+
+	b.timersMx.Lock()
+	b.timers[chat] = time.AfterFunc(AIDelay, func() {
+		b.HandleAITurn(chat)
+	})
+	b.timersMx.Unlock()
+}
+
+// StopTimer stops the turn timeout for the chat, if any
+func (b *Bot) StopTimer(chat int64) {
+	b.timersMx.Lock()
+	defer b.timersMx.Unlock()
+
+	if t, ok := b.timers[chat]; ok {
+		t.Stop()
+		delete(b.timers, chat)
+	}
+}
+
+// HandleTimeout handles an expired turn
+// started is the start of the turn the timer was armed for, if the turn changed meanwhile nothing is done
+func (b *Bot) HandleTimeout(chat int64, started time.Time) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	g, ok := b.Games[chat]
+	if !ok {
+		return
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	if g.GetState() == game.LOBBY || !g.TurnStarted.Equal(started) {
+		b.logger.Trace().Int64("chat_id", chat).Msg("Turn already changed, ignoring timeout")
+		return
+	}
+
+	b.logger.Info().Int64("chat_id", chat).Int("user_id", g.CurrentPlayer().ID).Msg("Turn timed out")
+
+	player := g.CurrentPlayer()
+	state := g.GetState()
+
+	if err := g.Timeout(); err != nil {
+		b.logger.Error().Err(err).Int64("chat_id", chat).Send()
+		return
+	}
+
+	var msg string
+	switch state {
+	case game.CHOOSE_COLOR:
+		msg = fmt.Sprintf("⏰ Tempo esgotado! Escolhi %s para %s", deck.COLOR_ICONS[g.GetCurrentCard().Color], Mention(player))
+	case game.CHOOSE_PLAYER:
+		msg = fmt.Sprintf("⏰ Tempo esgotado! %s trocou de mão com um jogador aleatório", Mention(player))
+	case game.CHOOSE_CARD, game.CHALLENGE:
+		// Timing out on a challenge accepts the +4, the pending cards are drawn
+		msg = fmt.Sprintf("⏰ Tempo esgotado! %s puxou %d carta(s) e passou a vez", Mention(player), g.LastDrawAmount)
+	default:
+		msg = fmt.Sprintf("⏰ Tempo esgotado! %s passou a vez", Mention(player))
+	}
+
+	b.tb.Send(&tb.Chat{ID: chat}, msg, tb.ModeMarkdown)
+	b.AfterMove(g)
+}
+
+// HandleAITurn makes the move of a computer-controlled current player
+// If the turn changed meanwhile (e.g. someone jumped in) nothing is done
+func (b *Bot) HandleAITurn(chat int64) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	g, ok := b.Games[chat]
+	if !ok {
+		return
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	if g.GetState() == game.LOBBY || !g.CurrentPlayer().IsAI() {
+		b.logger.Trace().Int64("chat_id", chat).Msg("Turn already changed, ignoring AI timer")
+		return
+	}
+
+	player := g.CurrentPlayer()
+
+	plays, err := ai.Move(g)
+	if err != nil {
+		// Shouldn't happen, but the game can't get stuck on a computer's turn
+		b.logger.Error().Err(err).Int64("chat_id", chat).Int("user_id", player.ID).Msg("AI move failed, timing out")
+
+		if err := g.Timeout(); err != nil {
+			b.logger.Error().Err(err).Int64("chat_id", chat).Send()
+			return
+		}
+
+		plays = []game.Play{g.RecentPlays[len(g.RecentPlays)-1]}
+	}
+
+	for _, p := range plays {
+		if p.Card != nil && p.Card.HasSticker() {
+			b.tb.Send(&tb.Chat{ID: chat}, &tb.Sticker{File: tb.File{FileID: p.Card.Sticker()}})
+		}
+
+		b.tb.Send(&tb.Chat{ID: chat}, "🤖 "+PlayText(p))
+	}
+
+	b.AfterMove(g)
+}
@@ -3,6 +3,8 @@ package deck
 import (
 	"fmt"
 	"log"
+	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -25,18 +27,21 @@ var Colors = map[string]Color{
 	"x": BLACK,
 }
 
+// Colors that can be chosen for a wild card
+var PlayableColors = []Color{RED, GREEN, BLUE, YELLOW}
+
 type CardType uint16
 
-// Possible card types
+// Built-in card types, registered with their names and effects by the game, see game.RegisterCard
 const (
 	NUMBER CardType = (1 << iota)
 	DRAW
 	REVERSE
 	SKIP
-	SKIPALL // TODO
+	SKIPALL // Skips everyone, the turn returns to the player
 	SWAP
-	SWAPALL    // TODO
-	DISCARDALL // TODO
+	SWAPALL    // Every hand is passed to the next player
+	DISCARDALL // Discards every card of the same color from the player's hand
 
 	WILD
 )
@@ -45,49 +50,53 @@ func (t CardType) Has(other CardType) bool {
 	return t&other != 0
 }
 
-func (t CardType) RequiresValue() bool {
-	return t.Has(NUMBER | DRAW)
-}
+// ParseCardType parses a card type from its .String() representation
+func ParseCardType(s string) (CardType, error) {
+	var t CardType
 
-func (t CardType) String() string {
-	var s = []string{}
+	for _, name := range strings.Split(s, "-") {
+		i := slices.IndexFunc(types, func(info TypeInfo) bool { return info.Name == name })
 
-	if t.Has(WILD) {
-		s = append(s, "wild")
-	}
+		if i < 0 {
+			return 0, fmt.Errorf("deck: unknown card type %q", name)
+		}
 
-	if t.Has(NUMBER) {
-		s = append(s, "number")
+		t |= types[i].Type
 	}
 
-	if t.Has(DRAW) {
-		s = append(s, "draw")
-	}
+	return t, nil
+}
 
-	if t.Has(REVERSE) {
-		s = append(s, "reverse")
+// RequiresValue checks if any of the types in t has a value, see TypeInfo.Valued
+func (t CardType) RequiresValue() bool {
+	for _, info := range types {
+		if t.Has(info.Type) && info.Valued {
+			return true
+		}
 	}
 
-	if t.Has(SKIP) {
-		s = append(s, "skip")
-	}
+	return false
+}
 
-	if t.Has(SKIPALL) {
-		s = append(s, "skipall")
-	}
+// String joins the names of the types in t, wild first and then by bit, so names don't depend on registration order
+func (t CardType) String() string {
+	var s = []string{}
 
-	if t.Has(SWAP) {
-		s = append(s, "swap")
+	if info, ok := LookupType(WILD); ok && t.Has(WILD) {
+		s = append(s, info.Name)
 	}
 
-	if t.Has(SWAPALL) {
-		s = append(s, "swapall")
+	for bit := CardType(1); bit != 0; bit <<= 1 {
+		if info, ok := LookupType(bit); ok && bit != WILD && t.Has(bit) {
+			s = append(s, info.Name)
+		}
 	}
 
 	return strings.Join(s, "-")
 }
 
 // This maps a card .String() representation to its sticker on telegram cache
+// Cards without a sticker here (e.g. skipall, swapall and discardall) are shown as text
 var STICKER_MAP = map[string]string{
 	"b_number_0": "CAACAgEAAxkBAAIBK2DJkaZl4bmgI47DRWr6xkPuR7eHAALUAQACVZ9RRkWe-hVeuGjbHwQ",
 	"b_number_1": "CAACAgEAAxkBAAIBLWDJka4R1V6-bf9iLC5oWLj1gE5hAAKeAgAC3_NIRkaHLYay2YL7HwQ",
@@ -230,6 +239,7 @@ var COLOR_ICONS = map[Color]string{
 
 // Card represents a single card in a UNO deck
 type Card struct {
+	ID    int `json:",omitempty"` // Unique in the deck, assigned when the card is added to it
 	Color Color
 	Type  CardType
 	Value int
@@ -315,6 +325,12 @@ func (c *Card) Sticker() string {
 	return s
 }
 
+// HasSticker checks if the card has a sticker on telegram cache
+func (c *Card) HasSticker() bool {
+	_, ok := STICKER_MAP[c.String()]
+	return ok
+}
+
 // StickerNotAvailable returns the card's faded sticker FileID
 func (c *Card) StickerNotAvailable() string {
 	s, ok := FADED_STICKER_MAP[c.String()]
@@ -335,9 +351,20 @@ type StackConfig struct {
 // CanPlayOnTop checks if c can be played on top of c2
 // If a draw is pending, only other DRAW cards can be played
 // Color must always match (or be a wild card). Other rules depends on config
+// Without a pending draw, any of c's types can also allow it, see TypeInfo.Playable
 func (c *Card) CanPlayOnTop(c2 *Card, draw_pending bool, config StackConfig) bool {
 	if !draw_pending {
-		return c.IsSpecial() || c.Color == c2.Color || (c.Type == c2.Type && c.Value == c2.Value)
+		if c.Color == c2.Color || (c.Type == c2.Type && c.Value == c2.Value) {
+			return true
+		}
+
+		for _, info := range types {
+			if c.Type.Has(info.Type) && info.Playable != nil && info.Playable(c, c2) {
+				return true
+			}
+		}
+
+		return false
 	}
 
 	if !config.CanStackDraws || !c.Type.Has(DRAW) {
@@ -356,13 +383,16 @@ func (c *Card) CanPlayOnTop(c2 *Card, draw_pending bool, config StackConfig) boo
 }
 
 // UID returns the card Unique Identifier
-// We use the cards pointer address here as it's always unique and it
-// won't change during a game lifetime as we are moving the same card around Deck, Game and Players' hands
+// It's the card ID, so it's the same after the game is saved and loaded again
 func (c *Card) UID() string {
-	return fmt.Sprintf("%p", c)
+	return strconv.Itoa(c.ID)
 }
 
-// Score returns the card score value according to this table:
+// ScoreTable maps card types to how many points they are worth
+// Number cards not in the table are worth their face value
+type ScoreTable map[CardType]int
+
+// DefaultScoreTable returns the standard points table, with the score of every registered type:
 //
 // | Card         | Value            |
 // | ------------ | ---------------- |
@@ -370,19 +400,81 @@ func (c *Card) UID() string {
 // | Draw 2       | 20               |
 // | Reverse      | 20               |
 // | Skip         | 20               |
+// | Skip All     | 20               |
+// | Swap         | 20               |
+// | Swap All     | 20               |
+// | Discard All  | 20               |
 // | Wild         | 50               |
 // | Draw Four    | 50               |
-func (c *Card) Score() int {
-	return 0
+func DefaultScoreTable() ScoreTable {
+	table := ScoreTable{WILD | DRAW: 50}
 
-	// Deprecated
-	// if c.IsSpecial() {
-	// 	return 50
-	// }
+	for _, info := range types {
+		if info.Score > 0 {
+			table[info.Type] = info.Score
+		}
+	}
 
-	// if c.Type > DRAW {
-	// 	return 20
-	// }
+	return table
+}
+
+// Score returns the card score value according to table
+// Types missing from the table are worth as much as the most valuable type they contain
+// Registered types the table doesn't know, e.g. tables saved before they existed, are worth their default score
+func (c *Card) Score(table ScoreTable) int {
+	if s, ok := table[c.Type]; ok {
+		return s
+	}
+
+	score := 0
+	if c.Type.Has(NUMBER) {
+		score = c.Value
+	}
+
+	for t, s := range table {
+		if c.Type&t == t && s > score {
+			score = s
+		}
+	}
+
+	for _, info := range types {
+		if _, ok := table[info.Type]; !ok && c.Type.Has(info.Type) && info.Score > score {
+			score = info.Score
+		}
+	}
+
+	return score
+}
+
+// ParseCard parses a card from its .String() representation
+func ParseCard(s string) (*Card, error) {
+	parts := strings.Split(s, "_")
+
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("deck: invalid card %q", s)
+	}
+
+	color, ok := Colors[parts[0]]
+	if !ok {
+		return nil, fmt.Errorf("deck: invalid card color %q", parts[0])
+	}
+
+	t, err := ParseCardType(parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	value := -1
+	if t.RequiresValue() {
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("deck: card %q requires a value", s)
+		}
+
+		value, err = strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("deck: invalid card value %q", parts[2])
+		}
+	}
 
-	// return int(c.Type)
+	return NewCard(color, t, value), nil
 }
@@ -0,0 +1,57 @@
+package deck_test
+
+import (
+	"testing"
+
+	"github.com/d-nery/catorce/pkg/deck"
+	"github.com/d-nery/catorce/pkg/game"
+)
+
+func TestParseCard(t *testing.T) {
+	for data := range game.DefaultConfig().DeckConfig.Cards {
+		c := deck.NewCard(data.Color, data.CardType, data.Value)
+
+		parsed, err := deck.ParseCard(c.String())
+		if err != nil {
+			t.Errorf("%s: %v", c, err)
+			continue
+		}
+
+		if parsed.Color != c.Color || parsed.Type != c.Type || parsed.Value != c.Value {
+			t.Errorf("%s parsed as %s", c, parsed)
+		}
+	}
+
+	for _, s := range []string{"", "r", "p_number_1", "r_bogus_1", "r_number", "r_number_x", "r_number_1_2"} {
+		if c, err := deck.ParseCard(s); err == nil {
+			t.Errorf("%q parsed as %s", s, c)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name  string
+		card  *deck.Card
+		table deck.ScoreTable
+		want  int
+	}{
+		{"number", deck.NewCard(deck.RED, deck.NUMBER, 7), deck.DefaultScoreTable(), 7},
+		{"draw two", deck.NewCard(deck.RED, deck.DRAW, 2), deck.DefaultScoreTable(), 20},
+		{"wild", deck.NewCard(deck.BLACK, deck.WILD, -1), deck.DefaultScoreTable(), 50},
+		{"wild draw four", deck.NewCard(deck.BLACK, deck.WILD|deck.DRAW, 4), deck.DefaultScoreTable(), 50},
+		{"skip two", deck.NewCard(deck.RED, game.SKIPTWO, -1), deck.DefaultScoreTable(), 20},
+		{"custom", deck.NewCard(deck.RED, deck.DRAW, 2), deck.ScoreTable{deck.DRAW: 10}, 10},
+		{"custom number", deck.NewCard(deck.RED, deck.NUMBER, 7), deck.ScoreTable{deck.NUMBER: 1}, 1},
+		// Combined types missing from the table are worth their most valuable type
+		{"combined", deck.NewCard(deck.BLACK, deck.WILD|deck.SWAP, -1), deck.ScoreTable{deck.WILD: 40, deck.SWAP: 30}, 40},
+		// Tables saved before a type existed use its default score
+		{"missing type", deck.NewCard(deck.RED, game.SKIPTWO, -1), deck.ScoreTable{deck.DRAW: 10}, 20},
+	}
+
+	for _, tt := range tests {
+		if got := tt.card.Score(tt.table); got != tt.want {
+			t.Errorf("%s: %s scored %d, want %d", tt.name, tt.card, got, tt.want)
+		}
+	}
+}
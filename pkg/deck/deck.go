@@ -3,7 +3,8 @@ package deck
 import (
 	"encoding/json"
 	"fmt"
-	"math/rand"
+	"sort"
+	"time"
 )
 
 // Deck is a group of cards and discarded cards
@@ -12,6 +13,15 @@ type Deck struct {
 	Graveyard []*Card
 
 	Config DeckConfig
+	NextID int     // ID of the next card added to the deck, see AssignID
+	Seed   int64   // Seed of the random source
+	Source *Source // Random source for shuffles, set by the game from its seed
+
+	// Commitment of the initial order, see Commit
+	Commitment string
+	Salt       string
+	Order      []string
+	Log        []DeckEvent
 }
 
 type CardData struct {
@@ -24,6 +34,45 @@ type DeckConfig struct {
 	Cards map[CardData]int
 }
 
+// Total returns the amount of cards in a full deck
+func (d *DeckConfig) Total() int {
+	total := 0
+
+	for _, amount := range d.Cards {
+		total += amount
+	}
+
+	return total
+}
+
+// SetAmount sets how many cards with color, type and value the deck has, 0 removes them from the deck
+func (d *DeckConfig) SetAmount(color Color, t CardType, value int, amount int) {
+	if d.Cards == nil {
+		d.Cards = map[CardData]int{}
+	}
+
+	key := CardData{Color: color, CardType: t, Value: value}
+
+	if amount <= 0 {
+		delete(d.Cards, key)
+		return
+	}
+
+	d.Cards[key] = amount
+}
+
+// SetColoredAmount sets how many cards with type and value the deck has for each playable color
+func (d *DeckConfig) SetColoredAmount(t CardType, value int, amount int) {
+	for _, c := range PlayableColors {
+		d.SetAmount(c, t, value, amount)
+	}
+}
+
+// ColoredAmount returns how many cards with type and value the deck has per color
+func (d *DeckConfig) ColoredAmount(t CardType, value int) int {
+	return d.Cards[CardData{Color: PlayableColors[0], CardType: t, Value: value}]
+}
+
 // New creates a new filled deck
 func New(config DeckConfig, half_deck bool) *Deck {
 	divider := 1
@@ -39,24 +88,71 @@ func New(config DeckConfig, half_deck bool) *Deck {
 		Config: config,
 	}
 
-	for card, amount := range config.Cards {
+	// Map order is random, the cards are sorted so the same seed always gives the same deck
+	keys := make([]CardData, 0, len(config.Cards))
+	for card := range config.Cards {
+		keys = append(keys, card)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Color != keys[j].Color {
+			return keys[i].Color < keys[j].Color
+		}
+
+		if keys[i].CardType != keys[j].CardType {
+			return keys[i].CardType < keys[j].CardType
+		}
+
+		return keys[i].Value < keys[j].Value
+	})
+
+	for _, card := range keys {
+		amount := config.Cards[card]
 		for i := 0; i < amount/divider; i++ {
-			deck.Cards = append(deck.Cards, NewCard(card.Color, card.CardType, card.Value))
+			c := NewCard(card.Color, card.CardType, card.Value)
+			deck.AssignID(c)
+			deck.Cards = append(deck.Cards, c)
 		}
 	}
 
 	return &deck
 }
 
+// AssignID gives c the next card ID of the deck, IDs start at 1
+func (d *Deck) AssignID(c *Card) {
+	if d.NextID == 0 {
+		d.NextID = 1
+	}
+
+	c.ID = d.NextID
+	d.NextID += 1
+}
+
+// SetSeed seeds the deck's random source
+func (d *Deck) SetSeed(seed int64) {
+	d.Seed = seed
+	d.Source = NewSource(seed)
+}
+
 // Merge adds other deck's cards to this deck
+// The cards get new IDs, so they don't repeat the ones already in the game
 func (d *Deck) Merge(other *Deck) {
+	for _, c := range other.Cards {
+		d.AssignID(c)
+	}
+
 	d.Cards = append(d.Cards, other.Cards...)
 	other.Cards = nil
 }
 
 // Shuffle shuffles all the cards in the deck
+// Decks without a source (e.g. saved before seeds existed) get one seeded from the current time
 func (d *Deck) Shuffle() {
-	rand.Shuffle(len(d.Cards), func(i, j int) {
+	if d.Source == nil {
+		d.Source = NewSource(time.Now().UnixNano())
+	}
+
+	d.Source.Rand().Shuffle(len(d.Cards), func(i, j int) {
 		d.Cards[i], d.Cards[j] = d.Cards[j], d.Cards[i]
 	})
 }
@@ -93,6 +189,12 @@ func (d *Deck) FillFromGraveyard() {
 		return
 	}
 
+	refill := make([]string, 0, d.Discarded())
+	for _, c := range d.Graveyard {
+		refill = append(refill, c.String())
+	}
+	d.log(DeckEvent{Refill: refill})
+
 	d.Cards = append(d.Cards, d.Graveyard...)
 	d.Graveyard = []*Card{}
 
@@ -118,12 +220,14 @@ func (d *Deck) Draw() *Card {
 	}
 
 	if d.Available() == 0 {
+		d.log(DeckEvent{Merge: true})
 		d.Merge(New(d.Config, true))
 		d.Shuffle()
 	}
 
 	card := d.Cards[0]
 	d.Cards = d.Cards[1:]
+	d.log(DeckEvent{Draw: card.String()})
 	return card
 }
 
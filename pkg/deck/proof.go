@@ -0,0 +1,179 @@
+package deck
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Possible proof errors
+var (
+	ErrCommitmentMismatch = errors.New("proof: commitment doesn't match the revealed seed and order")
+	ErrOrderMismatch      = errors.New("proof: revealed order doesn't match the seed")
+)
+
+// DeckEvent is an entry in the deck log, only one of the fields is set
+type DeckEvent struct {
+	Draw   string   `json:",omitempty"` // A card was drawn
+	Refill []string `json:",omitempty"` // The graveyard, in order, was put back and shuffled
+	Merge  bool     `json:",omitempty"` // A half deck was added and shuffled
+}
+
+// Proof reveals everything needed to check a game's deck against the commitment posted at the start
+type Proof struct {
+	Commitment string
+	Salt       string
+	Seed       int64
+	Config     DeckConfig
+	Order      []string    // Deck order right after the initial shuffle
+	Log        []DeckEvent // Everything that happened to the deck afterwards
+}
+
+// commitment hashes the salt, seed and deck order
+func commitment(salt string, seed int64, order []string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%s", salt, seed, strings.Join(order, ","))))
+	return hex.EncodeToString(sum[:])
+}
+
+// RandomSeed returns a seed that can't be guessed, so the commitment doesn't give away the deck
+func RandomSeed() int64 {
+	var b [8]byte
+
+	for {
+		if _, err := rand.Read(b[:]); err != nil {
+			panic(err)
+		}
+
+		seed := int64(uint64(b[0]) | uint64(b[1])<<8 | uint64(b[2])<<16 | uint64(b[3])<<24 |
+			uint64(b[4])<<32 | uint64(b[5])<<40 | uint64(b[6])<<48 | uint64(b[7]&0x7f)<<56)
+
+		if seed != 0 {
+			return seed
+		}
+	}
+}
+
+// Commit records the current deck order and returns a hash commitment of it, the seed and a random salt
+// Should be called right after the initial shuffle, every draw and reshuffle afterwards is logged
+func (d *Deck) Commit() string {
+	var salt [16]byte
+	if _, err := rand.Read(salt[:]); err != nil {
+		panic(err)
+	}
+
+	d.Salt = hex.EncodeToString(salt[:])
+	d.Order = make([]string, 0, len(d.Cards))
+	for _, c := range d.Cards {
+		d.Order = append(d.Order, c.String())
+	}
+
+	d.Log = []DeckEvent{}
+	d.Commitment = commitment(d.Salt, d.Seed, d.Order)
+
+	return d.Commitment
+}
+
+// Proof returns the proof for the committed deck
+func (d *Deck) Proof() *Proof {
+	return &Proof{
+		Commitment: d.Commitment,
+		Salt:       d.Salt,
+		Seed:       d.Seed,
+		Config:     d.Config,
+		Order:      d.Order,
+		Log:        d.Log,
+	}
+}
+
+// log adds an event to the deck log, if the deck was committed
+func (d *Deck) log(e DeckEvent) {
+	if d.Commitment != "" {
+		d.Log = append(d.Log, e)
+	}
+}
+
+// ReadProof reads a proof from a JSON file
+func ReadProof(path string) (*Proof, error) {
+	body, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var p Proof
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// Verify checks the proof against its commitment and replays the deck log
+// The deck is rebuilt from the config and seed, so every draw must match the committed order
+// and every reshuffle must match what the seed gives for the revealed graveyard
+func (p *Proof) Verify() error {
+	if commitment(p.Salt, p.Seed, p.Order) != p.Commitment {
+		return ErrCommitmentMismatch
+	}
+
+	d := New(p.Config, false)
+	d.SetSeed(p.Seed)
+	d.Shuffle()
+
+	if len(d.Cards) != len(p.Order) {
+		return ErrOrderMismatch
+	}
+
+	for i, c := range d.Cards {
+		if c.String() != p.Order[i] {
+			return ErrOrderMismatch
+		}
+	}
+
+	for i, e := range p.Log {
+		switch {
+		case e.Draw != "":
+			if d.Available() == 0 {
+				return fmt.Errorf("proof: entry %d: draw from an empty deck", i)
+			}
+
+			if c := d.Draw(); c.String() != e.Draw {
+				return fmt.Errorf("proof: entry %d: log says %s was drawn, deck gives %s", i, e.Draw, c)
+			}
+
+		case len(e.Refill) > 0:
+			if d.Available() != 0 {
+				return fmt.Errorf("proof: entry %d: refill with %d cards still in the deck", i, d.Available())
+			}
+
+			for _, s := range e.Refill {
+				c, err := ParseCard(s)
+				if err != nil {
+					return fmt.Errorf("proof: entry %d: %w", i, err)
+				}
+
+				d.Graveyard = append(d.Graveyard, c)
+			}
+
+			d.FillFromGraveyard()
+
+		case e.Merge:
+			if d.Available() != 0 {
+				return fmt.Errorf("proof: entry %d: merge with %d cards still in the deck", i, d.Available())
+			}
+
+			d.Merge(New(d.Config, true))
+			d.Shuffle()
+
+		default:
+			return fmt.Errorf("proof: entry %d: empty entry", i)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package deck_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/d-nery/catorce/pkg/deck"
+	"github.com/d-nery/catorce/pkg/game"
+)
+
+// playedDeck shuffles the default deck with seed, commits it as if the seed were committed,
+// and draws through a refill from the graveyard and a merge with a half deck
+func playedDeck(seed, committed int64) *deck.Deck {
+	d := deck.New(game.DefaultConfig().DeckConfig, false)
+	d.SetSeed(seed)
+	d.Shuffle()
+	d.Seed = committed
+	d.Commit()
+
+	for i := 0; i < 10; i++ {
+		d.Discard(d.Draw())
+	}
+
+	// Empties the deck, the next draw refills it with the 10 discarded cards and the one after them merges
+	for d.Available() > 0 {
+		d.Draw()
+	}
+
+	for i := 0; i < 11; i++ {
+		d.Draw()
+	}
+
+	return d
+}
+
+func TestProofVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		seed   int64 // Seed committed, the deck is shuffled with seed 1
+		tamper func(p *deck.Proof)
+		err    error
+	}{
+		{name: "valid", seed: 1, tamper: func(p *deck.Proof) {}},
+		{name: "seed", seed: 1, tamper: func(p *deck.Proof) { p.Seed += 1 }, err: deck.ErrCommitmentMismatch},
+		{name: "salt", seed: 1, tamper: func(p *deck.Proof) { p.Salt = "salt" }, err: deck.ErrCommitmentMismatch},
+		{
+			name:   "order",
+			seed:   1,
+			tamper: func(p *deck.Proof) { p.Order[0], p.Order[1] = p.Order[1], p.Order[0] },
+			err:    deck.ErrCommitmentMismatch,
+		},
+		{name: "committed seed didn't shuffle the deck", seed: 2, tamper: func(p *deck.Proof) {}, err: deck.ErrOrderMismatch},
+		{name: "draw", seed: 1, tamper: func(p *deck.Proof) { p.Log[0].Draw = p.Log[1].Draw }},
+		{name: "refill", seed: 1, tamper: func(p *deck.Proof) { refill(p)[0] = refill(p)[1] }},
+		{
+			name: "missing merge",
+			seed: 1,
+			tamper: func(p *deck.Proof) {
+				if n := len(p.Log); p.Log[n-2].Merge {
+					p.Log = append(p.Log[:n-2], p.Log[n-1])
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := playedDeck(1, tt.seed).Proof()
+			tt.tamper(p)
+			err := p.Verify()
+
+			switch {
+			case tt.name == "valid" && err != nil:
+				t.Errorf("valid proof failed: %v", err)
+			case tt.name != "valid" && err == nil:
+				t.Error("tampered proof passed")
+			case tt.err != nil && !errors.Is(err, tt.err):
+				t.Errorf("got %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+// refill returns the graveyard order of the proof's first refill
+func refill(p *deck.Proof) []string {
+	for _, e := range p.Log {
+		if len(e.Refill) > 0 {
+			return e.Refill
+		}
+	}
+
+	return nil
+}
+
+func TestReadProof(t *testing.T) {
+	body, err := json.Marshal(playedDeck(1, 1).Proof())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "proof.json")
+	if err := os.WriteFile(path, body, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := deck.ReadProof(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Verify(); err != nil {
+		t.Errorf("proof read from file failed: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package deck
+
+import (
+	"math/rand"
+)
+
+// Source is a small random source whose whole state is exported, so it survives persistence
+// and a game can be reproduced from its seed (splitmix64)
+type Source struct {
+	State uint64
+}
+
+// NewSource creates a new source from seed
+func NewSource(seed int64) *Source {
+	return &Source{State: uint64(seed)}
+}
+
+// Seed resets the source to seed
+func (s *Source) Seed(seed int64) {
+	s.State = uint64(seed)
+}
+
+// Uint64 returns the next pseudo-random number
+func (s *Source) Uint64() uint64 {
+	s.State += 0x9e3779b97f4a7c15
+
+	z := s.State
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+
+	return z ^ (z >> 31)
+}
+
+// Int63 returns the next non-negative pseudo-random number
+func (s *Source) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// Rand returns a *rand.Rand backed by the source
+// It holds no state of its own, so a new one can be created for every use
+func (s *Source) Rand() *rand.Rand {
+	return rand.New(s)
+}
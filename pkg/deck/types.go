@@ -0,0 +1,57 @@
+package deck
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TypeInfo describes a card type outside of the game rules: its name, its points and when it can be played
+// Every type, built-in or not, is registered by the game with what it does when played, see game.RegisterCard
+type TypeInfo struct {
+	Type   CardType
+	Name   string // Shown on cards and used to parse them, see CardType.String
+	Score  int    // Points in the default score table, 0 leaves the type out of it
+	Valued bool   // Cards of this type have a value, e.g. numbers and draws
+
+	// Playable reports if c can be played on top regardless of color and value, nil if it can't
+	Playable func(c, top *Card) bool
+}
+
+// types is the card type registry
+var types []TypeInfo
+
+// RegisterType adds a card type to the registry
+// It panics if the type isn't a single bit, if the name has card separators or if either is already taken
+func RegisterType(info TypeInfo) {
+	if info.Type == 0 || info.Type&(info.Type-1) != 0 {
+		panic(fmt.Sprintf("deck: card type %q must be a single bit", info.Name))
+	}
+
+	if info.Name == "" || strings.ContainsAny(info.Name, "-_") {
+		panic(fmt.Sprintf("deck: invalid card type name %q", info.Name))
+	}
+
+	for _, t := range types {
+		if t.Type == info.Type || t.Name == info.Name {
+			panic(fmt.Sprintf("deck: card type %q already registered", info.Name))
+		}
+	}
+
+	types = append(types, info)
+}
+
+// Types returns every registered card type, in registration order
+func Types() []TypeInfo {
+	return append([]TypeInfo{}, types...)
+}
+
+// LookupType returns the registered info of the single type t
+func LookupType(t CardType) (TypeInfo, bool) {
+	for _, info := range types {
+		if info.Type == t {
+			return info, true
+		}
+	}
+
+	return TypeInfo{}, false
+}
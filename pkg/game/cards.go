@@ -0,0 +1,232 @@
+package game
+
+import (
+	"slices"
+
+	"github.com/d-nery/catorce/pkg/deck"
+)
+
+// Effect is what a card type does in the game
+// A card applies the effect of each of its types, in registration order
+type Effect struct {
+	Type deck.CardType // Set by RegisterCard
+
+	// Requires are the types a card must also have for the effect to work, e.g. WILD for effects on the chosen color
+	Requires deck.CardType
+
+	// Play applies the effect when a card is played, before the turn ends, nil does nothing
+	// It can queue decisions and set the pending skips, see advance
+	Play func(g *Game, c *deck.Card)
+
+	// Start applies the effect when the card is the first one on the table, nil does nothing
+	Start func(g *Game, c *deck.Card)
+
+	// ColorChosen applies the part of the effect that depends on the color chosen for a wild card, nil does nothing
+	ColorChosen func(g *Game, c *deck.Card, color deck.Color)
+}
+
+// Extra card types, they are only in the deck if enabled in the chat's DeckConfig
+const (
+	SKIPTWO  deck.CardType = 1 << (9 + iota) // Skips the next two players
+	ROULETTE                                 // The next player draws until they get the chosen color, and loses their turn
+)
+
+// effects is the card effect registry, in the order they are applied
+var effects []Effect
+
+func init() {
+	RegisterCard(deck.TypeInfo{Type: deck.DRAW, Name: "draw", Score: 20, Valued: true}, Effect{
+		Play:  drawEffect,
+		Start: drawEffect,
+	})
+
+	RegisterCard(deck.TypeInfo{Type: deck.WILD, Name: "wild", Score: 50, Playable: anyCard}, Effect{
+		Play: chooseColorEffect,
+	})
+
+	RegisterCard(deck.TypeInfo{Type: deck.SKIP, Name: "skip", Score: 20}, Effect{
+		Play:  skipEffect,
+		Start: skipStart,
+	})
+
+	RegisterCard(deck.TypeInfo{Type: deck.SKIPALL, Name: "skipall", Score: 20}, Effect{
+		Play: skipAllEffect,
+	})
+
+	RegisterCard(deck.TypeInfo{Type: deck.DISCARDALL, Name: "discardall", Score: 20}, Effect{
+		Play:        discardAllEffect,
+		ColorChosen: discardAllColor,
+	})
+
+	RegisterCard(deck.TypeInfo{Type: deck.SWAP, Name: "swap", Score: 20}, Effect{
+		Play: swapEffect,
+	})
+
+	RegisterCard(deck.TypeInfo{Type: deck.SWAPALL, Name: "swapall", Score: 20}, Effect{
+		Play: swapAllEffect,
+	})
+
+	RegisterCard(deck.TypeInfo{Type: deck.NUMBER, Name: "number", Valued: true}, Effect{
+		Play: sevenOEffect,
+	})
+
+	RegisterCard(deck.TypeInfo{Type: deck.REVERSE, Name: "reverse", Score: 20}, Effect{
+		Play:  reverseEffect,
+		Start: reverseStart,
+	})
+
+	RegisterCard(deck.TypeInfo{Type: SKIPTWO, Name: "skiptwo", Score: 20}, Effect{
+		Play:  skipTwoEffect,
+		Start: skipTwoStart,
+	})
+
+	RegisterCard(deck.TypeInfo{Type: ROULETTE, Name: "roulette", Score: 50}, Effect{
+		Requires:    deck.WILD,
+		ColorChosen: rouletteColor,
+	})
+}
+
+// RegisterCard adds a card type with its effect, so it can be used in a DeckConfig
+// The effect is applied after the effects of every type registered before it
+func RegisterCard(info deck.TypeInfo, e Effect) {
+	deck.RegisterType(info)
+
+	e.Type = info.Type
+	effects = append(effects, e)
+}
+
+// ValidCardType checks if every type in t is registered and has the types its effect requires
+func ValidCardType(t deck.CardType) bool {
+	if t == 0 {
+		return false
+	}
+
+	known := deck.CardType(0)
+	for _, e := range effects {
+		known |= e.Type
+
+		if t.Has(e.Type) && t&e.Requires != e.Requires {
+			return false
+		}
+	}
+
+	return t&^known == 0
+}
+
+// anyCard is the playability rule of wild cards, they can be played on top of anything
+func anyCard(c, top *deck.Card) bool {
+	return true
+}
+
+func drawEffect(g *Game, c *deck.Card) {
+	g.DrawCount += c.Value
+}
+
+func chooseColorEffect(g *Game, c *deck.Card) {
+	g.Decisions = append(g.Decisions, CHOOSE_COLOR)
+}
+
+func skipEffect(g *Game, c *deck.Card) {
+	g.PendingSkips = 1
+}
+
+func skipStart(g *Game, c *deck.Card) {
+	g.EndTurn(0, CHOOSE_CARD)
+}
+
+// skipAllEffect skips everyone else, giving the turn back to the player
+func skipAllEffect(g *Game, c *deck.Card) {
+	g.PendingSkips = g.PlayerAmount() - 1
+}
+
+// discardAllEffect discards the card's color, wild cards discard the chosen color instead
+func discardAllEffect(g *Game, c *deck.Card) {
+	if !c.IsSpecial() {
+		g.DiscardAll(c.Color)
+	}
+}
+
+func discardAllColor(g *Game, c *deck.Card, color deck.Color) {
+	g.DiscardAll(color)
+}
+
+func swapEffect(g *Game, c *deck.Card) {
+	g.Decisions = append(g.Decisions, CHOOSE_PLAYER)
+}
+
+func swapAllEffect(g *Game, c *deck.Card) {
+	// Don't rotate if the game will be over
+	if len(g.CurrentPlayer().Hand) != 0 {
+		g.RotateHands()
+	}
+}
+
+// sevenOEffect swaps hands on a 7 and rotates them on a 0, if the chat plays Seven-O
+func sevenOEffect(g *Game, c *deck.Card) {
+	if !g.Config.SevenO || len(g.CurrentPlayer().Hand) == 0 {
+		return
+	}
+
+	switch c.Value {
+	case 7:
+		if !slices.Contains(g.Decisions, CHOOSE_PLAYER) {
+			g.Decisions = append(g.Decisions, CHOOSE_PLAYER)
+		}
+	case 0:
+		g.RotateHands()
+	}
+}
+
+// reverseEffect changes the play direction, with two players it works as a skip
+func reverseEffect(g *Game, c *deck.Card) {
+	if g.PlayerAmount() != 2 {
+		g.Reverse()
+	} else if g.PendingSkips == 0 {
+		g.PendingSkips = 1
+	}
+}
+
+func reverseStart(g *Game, c *deck.Card) {
+	if g.PlayerAmount() != 2 {
+		g.Reverse()
+	} else {
+		g.EndTurn(0, CHOOSE_CARD)
+	}
+}
+
+// skippedByTwo is how many players a skip two card skips, it never gives the turn back to whoever played it
+func skippedByTwo(g *Game) int {
+	return min(2, g.PlayerAmount()-1)
+}
+
+func skipTwoEffect(g *Game, c *deck.Card) {
+	g.PendingSkips = max(g.PendingSkips, skippedByTwo(g))
+}
+
+func skipTwoStart(g *Game, c *deck.Card) {
+	g.EndTurn(skippedByTwo(g)-1, CHOOSE_CARD)
+}
+
+// rouletteColor makes the next player draw until they get a card of the chosen color, up to the draw limit
+func rouletteColor(g *Game, c *deck.Card, color deck.Color) {
+	// The turn isn't over, so the next player is still the second one
+	p := g.Players[1%g.PlayerAmount()]
+	before := g.handSizes()
+
+	drawn := 0
+	for drawn < g.Config.DrawLimit {
+		card := g.Deck.Draw()
+		p.AddCard(card)
+		drawn += 1
+
+		if card.Color == color {
+			break
+		}
+	}
+
+	g.logger.Trace().Int("pid", p.ID).Int("amount", drawn).Msg("Roulette spun")
+	p.CardsDrawn += drawn
+	g.PendingSkips = max(g.PendingSkips, 1)
+	g.emit(RouletteSpun{Player: p, Color: color, Amount: drawn})
+	g.recheckCatorce(before)
+}
@@ -0,0 +1,50 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/d-nery/catorce/pkg/deck"
+)
+
+func TestCardTypes(t *testing.T) {
+	for _, info := range deck.Types() {
+		value := -1
+		if info.Valued {
+			value = 2
+		}
+
+		color := deck.RED
+		if info.Type == deck.WILD || info.Type == ROULETTE {
+			color = deck.BLACK
+		}
+
+		c := deck.NewCard(color, info.Type|effectRequires(info.Type), value)
+		parsed, err := deck.ParseCard(c.String())
+		if err != nil || parsed.Type != c.Type || parsed.Value != c.Value {
+			t.Errorf("%s parsed as %v, %v", c, parsed, err)
+		}
+
+		if !ValidCardType(c.Type) {
+			t.Errorf("%s isn't a valid card type", c.Type)
+		}
+	}
+
+	if ValidCardType(ROULETTE) {
+		t.Error("roulette without wild is a valid card type")
+	}
+
+	if ValidCardType(1 << 15) {
+		t.Error("unregistered card type is valid")
+	}
+}
+
+// effectRequires returns the types required by the effect of t
+func effectRequires(t deck.CardType) deck.CardType {
+	for _, e := range effects {
+		if e.Type == t {
+			return e.Requires
+		}
+	}
+
+	return 0
+}
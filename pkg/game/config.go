@@ -1,89 +1,154 @@
 package game
 
-import "github.com/d-nery/catorce/pkg/deck"
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/d-nery/catorce/pkg/deck"
+)
+
+// Possible config errors
+var (
+	ErrInvalidPlayerLimits = errors.New("config: invalid player limits")
+	ErrInvalidHandSize     = errors.New("config: invalid hand size")
+	ErrNotEnoughCards      = errors.New("config: deck doesn't have enough cards for every player")
+	ErrInvalidPenalty      = errors.New("config: invalid catorce penalty")
+	ErrInvalidCardType     = errors.New("config: deck has cards of unknown types or missing the types their effects need")
+)
 
 // Config holds game configuration
 type Config struct {
 	DeckConfig  deck.DeckConfig
 	StackConfig deck.StackConfig
+
+	MinPlayers  int  // Players needed to start the game
+	MaxPlayers  int  // Maximum amount of players in a game
+	HandSize    int  // Amount of cards each player starts with
+	RandomOrder bool // Players are seated randomly, otherwise in join order
+
+	TurnTimeout time.Duration // Idle players draw and pass after this long, 0 disables it
+	LateJoin    bool          // Players can join a game that is already running
+	SevenO      bool          // Playing a 7 swaps hands with a chosen player, playing a 0 rotates all hands
+	JumpIn      bool          // Players can play a card identical to the current one out of turn
+	Challenge   bool          // Wild draw cards can be challenged by the next player
+	TeamMode    bool          // Players play in two teams, seated alternately
+
+	DrawUntilPlayable bool // Drawing keeps going until a playable card is drawn
+	DrawLimit         int  // Maximum amount of cards drawn at once when DrawUntilPlayable is set
+
+	CatorcePenalty int           // Cards drawn by a player that doesn't call catorce in time
+	CatchGrace     time.Duration // Time a player has to call catorce before others can catch them
+
+	Scores      deck.ScoreTable // Points each card is worth at the end of a game
+	MatchTarget int             // Consecutive games form a match until someone reaches these points, 0 disables matches
 }
 
 func DefaultConfig() *Config {
+	deckConfig := deck.DeckConfig{}
+
+	deckConfig.SetColoredAmount(deck.NUMBER, 0, 1)
+	for v := 1; v <= 9; v++ {
+		deckConfig.SetColoredAmount(deck.NUMBER, v, 2)
+	}
+
+	deckConfig.SetColoredAmount(deck.SKIP, -1, 2)
+	deckConfig.SetColoredAmount(deck.REVERSE, -1, 2)
+	deckConfig.SetColoredAmount(deck.DRAW, 2, 2)
+
+	deckConfig.SetAmount(deck.BLACK, deck.WILD, -1, 4)
+	deckConfig.SetAmount(deck.BLACK, deck.WILD|deck.DRAW, 4, 4)
+
 	return &Config{
-		DeckConfig: deck.DeckConfig{
-			Cards: map[deck.CardData]int{
-				{deck.RED, deck.NUMBER, 0}:   1,
-				{deck.RED, deck.NUMBER, 1}:   2,
-				{deck.RED, deck.NUMBER, 2}:   2,
-				{deck.RED, deck.NUMBER, 3}:   2,
-				{deck.RED, deck.NUMBER, 4}:   2,
-				{deck.RED, deck.NUMBER, 5}:   2,
-				{deck.RED, deck.NUMBER, 6}:   2,
-				{deck.RED, deck.NUMBER, 7}:   2,
-				{deck.RED, deck.NUMBER, 8}:   2,
-				{deck.RED, deck.NUMBER, 9}:   2,
-				{deck.RED, deck.NUMBER, 9}:   2,
-				{deck.RED, deck.SKIP, -1}:    2,
-				{deck.RED, deck.REVERSE, -1}: 2,
-				{deck.RED, deck.DRAW, 2}:     2,
-
-				{deck.BLUE, deck.NUMBER, 0}:   1,
-				{deck.BLUE, deck.NUMBER, 1}:   2,
-				{deck.BLUE, deck.NUMBER, 2}:   2,
-				{deck.BLUE, deck.NUMBER, 3}:   2,
-				{deck.BLUE, deck.NUMBER, 4}:   2,
-				{deck.BLUE, deck.NUMBER, 5}:   2,
-				{deck.BLUE, deck.NUMBER, 6}:   2,
-				{deck.BLUE, deck.NUMBER, 7}:   2,
-				{deck.BLUE, deck.NUMBER, 8}:   2,
-				{deck.BLUE, deck.NUMBER, 9}:   2,
-				{deck.BLUE, deck.NUMBER, 9}:   2,
-				{deck.BLUE, deck.SKIP, -1}:    2,
-				{deck.BLUE, deck.REVERSE, -1}: 2,
-				{deck.BLUE, deck.DRAW, 2}:     2,
-
-				{deck.GREEN, deck.NUMBER, 0}:   1,
-				{deck.GREEN, deck.NUMBER, 1}:   2,
-				{deck.GREEN, deck.NUMBER, 2}:   2,
-				{deck.GREEN, deck.NUMBER, 3}:   2,
-				{deck.GREEN, deck.NUMBER, 4}:   2,
-				{deck.GREEN, deck.NUMBER, 5}:   2,
-				{deck.GREEN, deck.NUMBER, 6}:   2,
-				{deck.GREEN, deck.NUMBER, 7}:   2,
-				{deck.GREEN, deck.NUMBER, 8}:   2,
-				{deck.GREEN, deck.NUMBER, 9}:   2,
-				{deck.GREEN, deck.NUMBER, 9}:   2,
-				{deck.GREEN, deck.SKIP, -1}:    2,
-				{deck.GREEN, deck.REVERSE, -1}: 2,
-				{deck.GREEN, deck.DRAW, 2}:     2,
-
-				{deck.YELLOW, deck.NUMBER, 0}:   1,
-				{deck.YELLOW, deck.NUMBER, 1}:   2,
-				{deck.YELLOW, deck.NUMBER, 2}:   2,
-				{deck.YELLOW, deck.NUMBER, 3}:   2,
-				{deck.YELLOW, deck.NUMBER, 4}:   2,
-				{deck.YELLOW, deck.NUMBER, 5}:   2,
-				{deck.YELLOW, deck.NUMBER, 6}:   2,
-				{deck.YELLOW, deck.NUMBER, 7}:   2,
-				{deck.YELLOW, deck.NUMBER, 8}:   2,
-				{deck.YELLOW, deck.NUMBER, 9}:   2,
-				{deck.YELLOW, deck.NUMBER, 9}:   2,
-				{deck.YELLOW, deck.SKIP, -1}:    2,
-				{deck.YELLOW, deck.REVERSE, -1}: 2,
-				{deck.YELLOW, deck.DRAW, 2}:     2,
-
-				{deck.BLACK, deck.WILD, -1}:            4,
-				{deck.BLACK, deck.WILD | deck.DRAW, 4}: 4,
-			},
-		},
+		DeckConfig: deckConfig,
 		StackConfig: deck.StackConfig{
 			CanStackDraws:  false,
 			CanStackWild:   false,
 			CanStackBigger: false,
 		},
+		MinPlayers:  2,
+		MaxPlayers:  10,
+		HandSize:    7,
+		RandomOrder: true,
+
+		TurnTimeout: 0,
+		LateJoin:    false,
+		SevenO:      false,
+		JumpIn:      false,
+		Challenge:   false,
+		TeamMode:    false,
+
+		DrawUntilPlayable: false,
+		DrawLimit:         10,
+
+		CatorcePenalty: 4,
+		CatchGrace:     3 * time.Second,
+
+		Scores:      deck.DefaultScoreTable(),
+		MatchTarget: 0,
 	}
 }
 
+// Validate checks if the config is consistent
+// The deck must have enough cards to deal a full hand to the maximum amount of players, plus the first card,
+// and only cards whose types can be played, see ValidCardType
+func (c *Config) Validate() error {
+	if c.MinPlayers < 2 || c.MaxPlayers < c.MinPlayers {
+		return ErrInvalidPlayerLimits
+	}
+
+	if c.HandSize < 1 {
+		return ErrInvalidHandSize
+	}
+
+	if c.DeckConfig.Total() < c.MaxPlayers*c.HandSize+1 {
+		return ErrNotEnoughCards
+	}
+
+	if c.CatorcePenalty < 1 || c.CatchGrace < 0 {
+		return ErrInvalidPenalty
+	}
+
+	for card := range c.DeckConfig.Cards {
+		if !ValidCardType(card.CardType) {
+			return ErrInvalidCardType
+		}
+	}
+
+	return nil
+}
+
+// Clone returns a deep copy of the config
+func (c *Config) Clone() *Config {
+	clone := DefaultConfig()
+
+	body, err := json.Marshal(c)
+	if err == nil {
+		err = json.Unmarshal(body, clone)
+	}
+
+	if err != nil {
+		panic(err)
+	}
+
+	return clone
+}
+
+// UnmarshalJSON unmarshals a config on top of the default one,
+// so options missing from older saves keep their default values
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type config Config
+	aux := config(*DefaultConfig())
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	*c = Config(aux)
+	return nil
+}
+
 func (g *Game) SetConfig(config *Config) {
 	g.Config = config
+	g.record(JournalEntry{Event: JournalConfig, Config: config}, g.drawMark())
 }
@@ -3,6 +3,8 @@ package game
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/d-nery/catorce/pkg/deck"
 )
@@ -15,19 +17,39 @@ const (
 	DREW          GameState = "DREW"
 	CHOOSE_COLOR  GameState = "CHOOSE_COLOR"
 	CHOOSE_PLAYER GameState = "CHOOSE_PLAYER"
-	CATORCE       GameState = "CATORCE"
+	CHALLENGE     GameState = "CHALLENGE"
 )
 
-type EvtStartGame struct{}
+// States are all the game states, LOBBY is the initial one
+var States = []GameState{LOBBY, CHOOSE_CARD, DREW, CHOOSE_COLOR, CHOOSE_PLAYER, CHALLENGE}
+
+type EvtStartGame struct {
+	Seed int64 // Seed for the game's shuffles, 0 picks a random one
+}
 
 type EvtCatorce struct {
 	Player *Player
 }
 
+// EvtCatchCatorce is fired when Player catches everyone that didn't call catorce
+type EvtCatchCatorce struct {
+	Player *Player
+	Caught []*Player // Set when the event is accepted
+}
+
 type EvtAddPlayer struct {
 	Player *Player
 }
 
+type EvtChooseTeam struct {
+	Player *Player
+	Team   int
+}
+
+type EvtRemovePlayer struct {
+	Player *Player
+}
+
 type EvtCardPlayed struct {
 	Player *Player
 	Card   *deck.Card
@@ -47,6 +69,10 @@ type EvtDrawCard struct {
 	Player *Player
 }
 
+type EvtChallenge struct {
+	Player *Player
+}
+
 type EvtPass struct {
 	Player *Player
 }
@@ -56,144 +82,318 @@ type EventError error
 // Possible Event Errors
 var (
 	ErrNotEnoughPlayers EventError = errors.New("fsm: not enough players")
+	ErrUnbalancedTeams  EventError = errors.New("fsm: teams must have the same amount of players")
+	ErrNotTeamMode      EventError = errors.New("fsm: game is not in team mode")
+	ErrInvalidTeam      EventError = errors.New("fsm: invalid team")
+	ErrInvalidConfig    EventError = errors.New("fsm: invalid game config")
 	ErrEventNotCovered  EventError = errors.New("fsm: event not covered in current state")
 	ErrMaxPlayers       EventError = errors.New("fsm: maximum number of players reached")
 	ErrWrongPlayer      EventError = errors.New("fsm: it's not this player turn")
+	ErrPlayerNotFound   EventError = errors.New("fsm: player is not in the game")
 	ErrCantPlayCard     EventError = errors.New("fsm: illegal card")
 	ErrCantChooseColor  EventError = errors.New("fsm: current card is not special, can't change color")
 	ErrNoCatorcePending EventError = errors.New("fsm: no catorces pending")
+	ErrCatchTooSoon     EventError = errors.New("fsm: catorce can't be caught yet")
+	ErrCantChallenge    EventError = errors.New("fsm: last card can't be challenged")
 	ErrUnknownEvent     EventError = errors.New("fsm: unknown event")
 )
 
+// FireEvent fires evt on the game, accepted events are appended to the journal
+// A snapshot of the game before each accepted event is kept, so it can be undone
+// Leaving a running game is final, the player's stats are already saved, so the moves before it can't be undone
 func (g *Game) FireEvent(evt interface{}) EventError {
+	mark := g.drawMark()
+	snap := g.snapshot()
+	_, leaving := evt.(*EvtRemovePlayer)
+	leaving = leaving && g.State != LOBBY
+
+	if err := g.fireEvent(evt); err != nil {
+		if !g.muted {
+			g.outcomes = nil
+		}
+		return err
+	}
+
+	if play, ok := g.playFor(evt); ok {
+		g.addPlay(play, mark)
+	}
+
+	if leaving {
+		g.history = nil
+	} else {
+		g.pushHistory(snap)
+	}
+
+	g.record(journalEntry(evt), mark)
+
+	// Events fired during a timeout are dispatched with it
+	if !g.muted {
+		g.dispatch()
+	}
+
+	return nil
+}
+
+func (g *Game) fireEvent(evt interface{}) EventError {
 	g.logger.Debug().Str("event", fmt.Sprintf("%T", evt)).Str("current_state", string(g.State)).Msg("New event received")
 
-	switch e := evt.(type) {
-	case *EvtStartGame:
-		if g.State != LOBBY {
-			g.logger.Trace().Msg("ErrEventNotCovered for EvtStartGame")
-			return ErrEventNotCovered
-		}
+	name := EventName(evt)
+	if _, ok := eventNames[name]; !ok {
+		g.logger.Trace().Msg("ErrUnknownEvent")
+		return ErrUnknownEvent
+	}
+
+	t, ok := transitionTable[g.State][name]
+	if !ok {
+		g.logger.Trace().Msgf("ErrEventNotCovered for %s", name)
+		return ErrEventNotCovered
+	}
+
+	if err := t.guard(g, evt); err != nil {
+		g.logger.Trace().Err(err).Msgf("Guard failed for %s", name)
+		return err
+	}
+
+	from := g.State
+	t.action(g, evt)
 
-		if g.PlayerAmount() < 2 {
-			g.logger.Trace().Msg("ErrNotEnoughPlayers for EvtStartGame")
-			return ErrNotEnoughPlayers
+	if !slices.Contains(t.To, g.State) {
+		g.logger.Error().Str("event", name).Str("from", string(from)).Str("to", string(g.State)).Msg("Transition to an undeclared state")
+	}
+
+	return nil
+}
+
+// EventName returns the name of an event's type, e.g. EvtCardPlayed
+func EventName(evt interface{}) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", evt), "*game.")
+}
+
+// guardStartGame checks the config, the amount of players and, in team mode, if teams can be balanced
+func (g *Game) guardStartGame(e *EvtStartGame) EventError {
+	if err := g.Config.Validate(); err != nil {
+		return ErrInvalidConfig
+	}
+
+	if g.PlayerAmount() < g.Config.MinPlayers {
+		return ErrNotEnoughPlayers
+	}
+
+	if g.Config.TeamMode && !g.TeamsCanBalance() {
+		return ErrUnbalancedTeams
+	}
+
+	return nil
+}
+
+func (g *Game) startGame(e *EvtStartGame) {
+	if g.Config.TeamMode {
+		g.BalanceTeams()
+	} else {
+		// Team mode may have been turned off after players chose teams
+		for _, p := range g.Players {
+			p.Team = NO_TEAM
 		}
+	}
+
+	if e.Seed == 0 {
+		e.Seed = deck.RandomSeed()
+	}
 
-		g.ResetDeck()
-		g.Deck.Shuffle()
+	g.SetSeed(e.Seed)
+	g.ResetDeck()
+	g.Deck.Shuffle()
+	g.Deck.Commit()
+
+	if g.Config.RandomOrder {
 		g.ShufflePlayers()
-		g.DistributeCards()
-		g.PlayFirstCard()
+	} else if g.Config.TeamMode {
+		g.SeatTeams()
+	}
 
-		g.logger.Debug().Str("from", string(g.State)).Str("to", "CHOOSE_CARD").Msg("Changing state")
-		g.State = CHOOSE_CARD
-		return nil
+	g.DistributeCards()
+	g.PlayFirstCard()
 
-	case *EvtAddPlayer:
-		if g.State != LOBBY {
-			g.logger.Trace().Msg("ErrEventNotCovered for EvtAddPlayer")
-			return ErrEventNotCovered
-		}
+	g.logger.Debug().Str("from", string(g.State)).Str("to", "CHOOSE_CARD").Msg("Changing state")
+	g.State = CHOOSE_CARD
+}
+
+// guardAddPlayer checks late joins and the player limit
+func (g *Game) guardAddPlayer(e *EvtAddPlayer) EventError {
+	// Seats alternate between teams, a late player would break that
+	if g.State != LOBBY && (!g.Config.LateJoin || g.Config.TeamMode) {
+		return ErrEventNotCovered
+	}
+
+	if g.PlayerAmount() >= g.Config.MaxPlayers {
+		return ErrMaxPlayers
+	}
 
+	return nil
+}
+
+func (g *Game) addPlayer(e *EvtAddPlayer) {
+	if g.State == LOBBY {
 		g.AddPlayer(e.Player)
-		return nil
+	} else {
+		g.AddLatePlayer(e.Player)
+	}
+}
 
-	case *EvtCardPlayed:
-		if g.State != CHOOSE_CARD && g.State != DREW {
-			g.logger.Trace().Msg("ErrEventNotCovered for EvtCardPlayed")
-			return ErrEventNotCovered
-		}
+func (g *Game) guardChooseTeam(e *EvtChooseTeam) EventError {
+	if !g.Config.TeamMode {
+		return ErrNotTeamMode
+	}
 
-		if e.Player != g.CurrentPlayer() {
-			g.logger.Trace().Msg("ErrWrongPlayer for EvtCardPlayed")
-			return ErrWrongPlayer
-		}
+	if e.Player == nil || g.GetPlayer(e.Player.ID) != e.Player {
+		return ErrPlayerNotFound
+	}
 
-		c := e.Card
-		if !c.CanPlayOnTop(g.GetCurrentCard(), g.DrawCounter() > 0, g.Config.StackConfig) {
-			g.logger.Trace().Str("card", c.String()).Str("current", g.GetCurrentCard().String()).Msg("ErrCantPlayCard for EvtCardPlayed")
-			return ErrCantPlayCard
-		}
+	if e.Team != TEAM_A && e.Team != TEAM_B {
+		return ErrInvalidTeam
+	}
 
-		e.Player.RemoveCard(c)
-		g.PlayCard(c)
+	return nil
+}
 
-		return nil
+func (g *Game) chooseTeam(e *EvtChooseTeam) {
+	g.SetTeam(e.Player, e.Team)
+}
 
-	case *EvtDrawCard:
-		if g.State != CHOOSE_CARD {
-			g.logger.Trace().Msg("ErrEventNotCovered for EvtDrawCard")
-			return ErrEventNotCovered
-		}
+func (g *Game) guardRemovePlayer(e *EvtRemovePlayer) EventError {
+	if e.Player == nil || g.GetPlayer(e.Player.ID) != e.Player {
+		return ErrPlayerNotFound
+	}
 
-		p := e.Player
+	return nil
+}
+
+func (g *Game) removePlayer(e *EvtRemovePlayer) {
+	g.RemovePlayer(e.Player)
+}
 
-		if p != g.CurrentPlayer() {
-			g.logger.Trace().Msg("ErrWrongPlayer for EvtDrawCard")
+// guardCardPlayed checks if the card can be played, either on the player's turn or jumping in
+func (g *Game) guardCardPlayed(e *EvtCardPlayed) EventError {
+	if e.Player != g.CurrentPlayer() {
+		if !g.CanJumpIn(e.Player, e.Card) {
 			return ErrWrongPlayer
 		}
 
-		g.DrawCard()
 		return nil
+	}
 
-	case *EvtPass:
-		if g.State != DREW {
-			g.logger.Trace().Msg("ErrEventNotCovered for EvtPass")
-			return ErrEventNotCovered
-		}
+	if !e.Card.CanPlayOnTop(g.GetCurrentCard(), g.DrawCounter() > 0, g.Config.StackConfig) {
+		g.logger.Trace().Str("card", e.Card.String()).Str("current", g.GetCurrentCard().String()).Msg("Card can't be played")
+		return ErrCantPlayCard
+	}
 
-		g.EndTurn(false, CHOOSE_CARD)
-		return nil
+	return nil
+}
 
-	case *EvtColorChosen:
-		if g.State != CHOOSE_COLOR {
-			g.logger.Trace().Msg("ErrEventNotCovered for EvtColorChosen")
-			return ErrEventNotCovered
-		}
+func (g *Game) cardPlayed(e *EvtCardPlayed) {
+	jumped := e.Player != g.CurrentPlayer()
+	if jumped {
+		g.JumpIn(e.Player)
+	}
 
-		if !g.GetCurrentCard().IsSpecial() {
-			g.logger.Trace().Msg("ErrCantChooseColor for EvtColorChosen")
-			return ErrCantChooseColor
-		}
+	e.Player.RemoveCard(e.Card)
+	g.emit(CardPlayed{Player: e.Player, Card: e.Card, JumpedIn: jumped})
+	g.PlayCard(e.Card)
+}
 
-		g.CurrentCard.SetColor(e.Color)
-		g.EndTurn(false, CHOOSE_CARD)
-		return nil
+func (g *Game) guardDrawCard(e *EvtDrawCard) EventError {
+	if e.Player != g.CurrentPlayer() {
+		return ErrWrongPlayer
+	}
 
-	case *EvtPlayerSwapChosen:
-		if g.State != CHOOSE_PLAYER {
-			g.logger.Trace().Msg("ErrEventNotCovered for EvtColorChosen")
-			return ErrEventNotCovered
-		}
+	return nil
+}
 
-		target := g.GetPlayer(e.Target)
-		g.SwapHands(g.CurrentPlayer(), target)
+func (g *Game) drawCard(e *EvtDrawCard) {
+	g.DrawCard()
+}
 
-		g.EndTurn(false, CHOOSE_CARD)
-		return nil
+func (g *Game) pass(e *EvtPass) {
+	g.EndTurn(0, CHOOSE_CARD)
+}
 
-	case *EvtCatorce:
-		if g.State != CHOOSE_CARD {
-			g.logger.Trace().Msg("ErrEventNotCovered for EvtCatorce")
-			return ErrEventNotCovered
-		}
+func (g *Game) guardColorChosen(e *EvtColorChosen) EventError {
+	if !g.GetCurrentCard().IsSpecial() {
+		return ErrCantChooseColor
+	}
 
-		if !g.HasPendingCatorce() {
-			g.logger.Trace().Msg("ErrNoCatorcePending for EvtCatorce")
-			return ErrNoCatorcePending
-		}
+	return nil
+}
 
-		if e.Player.ID != g.PlayerCatorce {
-			g.logger.Trace().Msg("ErrWrongPlayer for EvtCatorce")
-			return ErrWrongPlayer
-		}
+func (g *Game) colorChosen(e *EvtColorChosen) {
+	g.ChooseColor(e.Color)
+}
 
-		g.PlayerCatorce = 0
-		return nil
+func (g *Game) guardChallenge(e *EvtChallenge) EventError {
+	if e.Player != g.CurrentPlayer() {
+		return ErrWrongPlayer
+	}
 
-	default:
-		g.logger.Trace().Msg("ErrUnknownEvent")
-		return ErrUnknownEvent
+	if g.GetPlayer(g.PreviousPlayer) == nil {
+		return ErrCantChallenge
+	}
+
+	return nil
+}
+
+func (g *Game) challenge(e *EvtChallenge) {
+	g.Challenge()
+}
+
+func (g *Game) guardPlayerSwapChosen(e *EvtPlayerSwapChosen) EventError {
+	if target := g.GetPlayer(e.Target); target == nil || target == g.CurrentPlayer() {
+		return ErrPlayerNotFound
+	}
+
+	return nil
+}
+
+func (g *Game) playerSwapChosen(e *EvtPlayerSwapChosen) {
+	g.ChooseSwapTarget(g.GetPlayer(e.Target))
+}
+
+func (g *Game) guardCatorce(e *EvtCatorce) EventError {
+	if !g.HasPendingCatorce() {
+		return ErrNoCatorcePending
 	}
+
+	if !g.MustCallCatorce(e.Player) {
+		return ErrWrongPlayer
+	}
+
+	return nil
+}
+
+func (g *Game) catorce(e *EvtCatorce) {
+	delete(g.PendingCatorces, e.Player.ID)
+}
+
+func (g *Game) guardCatchCatorce(e *EvtCatchCatorce) EventError {
+	if !g.HasPendingCatorce() {
+		return ErrNoCatorcePending
+	}
+
+	if e.Player == nil || g.GetPlayer(e.Player.ID) != e.Player {
+		return ErrWrongPlayer
+	}
+
+	// Nobody can catch themselves
+	if g.MustCallCatorce(e.Player) && len(g.PendingCatorces) == 1 {
+		return ErrWrongPlayer
+	}
+
+	if !g.CanCatch(e.Player) {
+		return ErrCatchTooSoon
+	}
+
+	return nil
+}
+
+func (g *Game) catchCatorce(e *EvtCatchCatorce) {
+	e.Caught = g.CatchCatorce(e.Player)
 }
@@ -2,36 +2,66 @@ package game
 
 import (
 	"fmt"
+	"io"
 	"math/rand"
 	"slices"
 	"strings"
 	"sync"
 	"time"
 
-	tb "gopkg.in/tucnak/telebot.v2"
-
 	"github.com/d-nery/catorce/pkg/deck"
 	"github.com/rs/zerolog"
 )
 
 type Game struct {
-	Chat          int64
-	Players       []*Player
-	Deck          *deck.Deck
-	State         GameState
-	DrawCount     int
-	CurrentCard   *deck.Card
-	PlayerCatorce int
-	Config        *Config
+	Chat        int64
+	Players     []*Player
+	Deck        *deck.Deck
+	State       GameState
+	DrawCount   int
+	CurrentCard *deck.Card
+
+	// Players that must call catorce and since when, they can be caught after the config's grace window
+	PendingCatorces map[int]time.Time
+
+	// Choices the current player still has to make for the card they played, in order, see PlayCard
+	Decisions    []GameState
+	PendingSkips int // Players skipped once the decisions are made
+
+	// Top card before the current one and who played the current one, used for challenges
+	PreviousCard   *deck.Card
+	PreviousPlayer int
+
+	Winner int
+	Config *Config
+
+	Seed   int64        // Seed of the game's random source, the same seed and moves always give the same game
+	Source *deck.Source // Random source for everything but the deck, which has its own
+
+	// Random source for computer-controlled players, kept apart from Source
+	// Their decisions are journaled as the events they make, so drawing from Source would make replays diverge
+	AISource *deck.Source
 
-	TurnStarted time.Time
+	TurnStarted    time.Time
+	LastDrawAmount int    // Amount of cards drawn by the last draw
+	RecentPlays    []Play // Last public plays, for spectators
 
 	// Current game stats, are added to overall when game is over
 	Rounds              int
 	P2Sequence          int
 	P4Played            int
+	LargestDraw         int
 	LargestResponseTime time.Duration
 
+	JournalPath string         // File the journal is appended to, see OpenJournal
+	journal     io.WriteCloser // Open journal, if any
+	muted       bool           // Events aren't journaled nor kept in history while set, e.g. during a timeout
+	history     [][]byte       // Snapshots before the last accepted events, see Undo
+	noUndo      bool           // No snapshots are kept, see DisableUndo
+	listeners   []Listener     // Receive the outcomes of accepted events, see AddListener
+	outcomes    []Outcome      // Outcomes of the event being applied, not dispatched yet
+
+	clock  func() time.Time // Current time, replays use the journal's
 	logger zerolog.Logger
 	mx     sync.Mutex
 }
@@ -39,18 +69,20 @@ type Game struct {
 func New(chat int64, logger zerolog.Logger, config *Config) *Game {
 	logger.Trace().Int64("chat", chat).Msg("Creating new game")
 	return &Game{
-		Chat:          chat,
-		Players:       []*Player{},
-		Deck:          nil,
-		State:         LOBBY,
-		DrawCount:     0,
-		CurrentCard:   nil,
-		PlayerCatorce: 0,
-		Rounds:        0,
-		P2Sequence:    0,
-		P4Played:      0,
-		TurnStarted:   time.Time{},
-		Config:        config,
+		Chat:        chat,
+		Players:     []*Player{},
+		Deck:        nil,
+		State:       LOBBY,
+		DrawCount:   0,
+		CurrentCard: nil,
+		Winner:      0,
+		Rounds:      0,
+		P2Sequence:  0,
+		P4Played:    0,
+		TurnStarted: time.Time{},
+		Config:      config,
+
+		PendingCatorces: map[int]time.Time{},
 
 		logger: logger.With().Int64("game_chat_id", chat).Logger(),
 	}
@@ -68,6 +100,32 @@ func (g *Game) SetLogger(logger zerolog.Logger) {
 	g.logger = logger.With().Int64("game_chat_id", g.Chat).Logger()
 }
 
+// SetSeed seeds the game's random source
+func (g *Game) SetSeed(seed int64) {
+	g.logger.Trace().Int64("seed", seed).Msg("Seeding game")
+	g.Seed = seed
+	g.Source = deck.NewSource(seed)
+	g.AISource = deck.NewSource(int64(deck.NewSource(seed).Uint64()))
+}
+
+// Rand returns a random generator backed by the game's source
+func (g *Game) Rand() *rand.Rand {
+	if g.Source == nil {
+		g.SetSeed(time.Now().UnixNano())
+	}
+
+	return g.Source.Rand()
+}
+
+// AIRand returns a random generator for computer-controlled players, see AISource
+func (g *Game) AIRand() *rand.Rand {
+	if g.AISource == nil {
+		g.AISource = deck.NewSource(time.Now().UnixNano())
+	}
+
+	return g.AISource.Rand()
+}
+
 func (g *Game) CurrentPlayer() *Player {
 	if len(g.Players) == 0 {
 		return nil
@@ -96,6 +154,11 @@ func (g *Game) PlayerAmount() int {
 
 func (g *Game) AddPlayer(p *Player) {
 	g.logger.Trace().Msg("Adding player")
+
+	if g.Config.TeamMode {
+		p.Team = g.SmallestTeam()
+	}
+
 	g.Players = append(g.Players, p)
 
 	if g.logger.GetLevel() <= zerolog.TraceLevel {
@@ -109,13 +172,89 @@ func (g *Game) AddPlayer(p *Player) {
 	}
 }
 
+// AddLatePlayer adds a player to a running game
+// The player is dealt a new hand and seated just before the current player, so they play last in this round
+// Games in team mode don't take late players, see guardAddPlayer
+func (g *Game) AddLatePlayer(p *Player) {
+	g.logger.Trace().Int("pid", p.ID).Msg("Adding player to running game")
+
+	for i := 0; i < g.Config.HandSize; i++ {
+		p.AddCard(g.Deck.Draw())
+	}
+
+	// The current player is always the first, so the last one is right before them
+	g.AddPlayer(p)
+}
+
+// RemovePlayer removes a player from the game
+// During the game their hand goes back to the deck graveyard and, if it was their turn,
+// the turn passes to the next player. The game is over if only one player remains
+func (g *Game) RemovePlayer(p *Player) {
+	g.logger.Trace().Int("pid", p.ID).Msg("Removing player")
+
+	wasCurrent := p == g.CurrentPlayer()
+	points := p.CurrentHandPoints(g.Config.Scores)
+	g.Players = slices.DeleteFunc(g.Players, func(other *Player) bool {
+		return other == p
+	})
+
+	if g.State == LOBBY {
+		return
+	}
+
+	for _, c := range p.Hand {
+		g.Deck.Discard(c)
+	}
+	p.Hand = nil
+	g.emit(PlayerLeft{Player: p, Points: points})
+
+	delete(g.PendingCatorces, p.ID)
+
+	if g.PlayerAmount() == 1 {
+		g.logger.Trace().Int("pid", g.CurrentPlayer().ID).Msg("Only one player left")
+		g.win()
+		return
+	}
+
+	if p.Team != NO_TEAM && len(g.TeamMembers(p.Team)) == 0 {
+		g.logger.Trace().Int("team", p.Team).Msg("No players left on team")
+		g.win()
+		return
+	}
+
+	if !wasCurrent {
+		return
+	}
+
+	switch g.State {
+	case CHOOSE_CARD, DREW, CHALLENGE:
+		// The pending draws were for the player that left
+		g.DrawCount = 0
+	case CHOOSE_COLOR:
+		g.CurrentCard.SetColor(deck.PlayableColors[g.Rand().Intn(len(deck.PlayableColors))])
+	}
+
+	// Their remaining decisions go with them
+	g.Decisions = nil
+	g.PendingSkips = 0
+
+	// The removed player was the first, so the next one is already in place
+	g.logger.Debug().Str("from", string(g.State)).Str("to", "CHOOSE_CARD").Msg("Changing state")
+	g.State = CHOOSE_CARD
+	g.TurnStarted = time.Now()
+}
+
 func (g *Game) ShufflePlayers() {
 	g.logger.Trace().Msg("Shuffling players")
 
-	rand.Shuffle(len(g.Players), func(i, j int) {
+	g.Rand().Shuffle(len(g.Players), func(i, j int) {
 		g.Players[i], g.Players[j] = g.Players[j], g.Players[i]
 	})
 
+	if g.Config.TeamMode {
+		g.SeatTeams()
+	}
+
 	if g.logger.GetLevel() <= zerolog.TraceLevel {
 		var out strings.Builder
 
@@ -144,19 +283,9 @@ func (g *Game) PlayFirstCard() {
 
 	g.logger.Trace().Str("card", g.CurrentCard.String()).Msg("First card played")
 
-	if g.CurrentCard.Type.Has(deck.DRAW) {
-		g.DrawCount += g.CurrentCard.Value
-	}
-
-	if g.CurrentCard.Type.Has(deck.SKIP) {
-		g.EndTurn(false, CHOOSE_CARD)
-	}
-
-	if g.CurrentCard.Type.Has(deck.REVERSE) {
-		if g.PlayerAmount() != 2 {
-			g.Reverse()
-		} else {
-			g.EndTurn(false, CHOOSE_CARD)
+	for _, e := range effects {
+		if e.Start != nil && g.CurrentCard.Type.Has(e.Type) {
+			e.Start(g, g.CurrentCard)
 		}
 	}
 
@@ -170,7 +299,7 @@ func (g *Game) DistributeCards() {
 		return
 	}
 
-	for i := 0; i < 7; i++ {
+	for i := 0; i < g.Config.HandSize; i++ {
 		for _, p := range g.Players {
 			p.AddCard(g.Deck.Draw())
 		}
@@ -180,17 +309,7 @@ func (g *Game) DistributeCards() {
 func (g *Game) PlayCard(c *deck.Card) {
 	g.logger.Trace().Msg("Playing card")
 
-	if g.HasPendingCatorce() {
-		p := g.GetPlayer(g.PlayerCatorce)
-		g.logger.Trace().Str("player_name", p.Name).Msg("There's a pending catorce!")
-		p.CatorcesMissed += 1
-
-		for i := 0; i < 4; i++ {
-			card := g.Deck.Draw()
-			p.AddCard(card)
-		}
-		g.PlayerCatorce = 0
-	}
+	g.ApplyCatorcePenalty()
 
 	g.CurrentPlayer().CardsPlayed += 1
 	turnDuration := time.Since(g.TurnStarted)
@@ -200,60 +319,76 @@ func (g *Game) PlayCard(c *deck.Card) {
 		g.LargestResponseTime = turnDuration
 	}
 
+	// Keep a copy, discarding resets wild cards colors
+	previous := *g.CurrentCard
+	g.PreviousCard = &previous
+	g.PreviousPlayer = g.CurrentPlayer().ID
+
 	g.Deck.Discard(g.CurrentCard)
 	g.CurrentCard = c
 
-	jump := false
-	nextState := CHOOSE_CARD
+	// Cards can combine effects, the ones that need a choice are queued and made in order
+	g.Decisions = []GameState{}
+	g.PendingSkips = 0
 
-	if c.Type.Has(deck.DRAW) {
-		g.DrawCount += c.Value
+	for _, e := range effects {
+		if e.Play != nil && c.Type.Has(e.Type) {
+			e.Play(g, c)
+		}
 	}
 
-	if c.Type.Has(deck.WILD) {
-		nextState = CHOOSE_COLOR
-	}
+	g.advance()
+}
 
-	if c.Type.Has(deck.SKIP) {
-		jump = true
+// advance moves the turn on after the current player played a card or made a decision
+// Pending decisions are made first, the turn ends with the skips once there are none left
+// A player that emptied their hand wins in EndTurn, without making the remaining decisions
+func (g *Game) advance() {
+	if len(g.Decisions) > 0 {
+		next := g.Decisions[0]
+		g.Decisions = g.Decisions[1:]
+		g.EndTurn(0, next)
+		return
 	}
 
-	if c.Type.Has(deck.SWAP) {
-		// Don't enter swap state if the game will be over
-		if len(g.CurrentPlayer().Hand) != 0 {
-			nextState = CHOOSE_PLAYER // TODO: Possible conflict in states if a card is a WILD SWAP, check
-		}
-	}
+	skips := g.PendingSkips
+	g.PendingSkips = 0
 
-	if c.Type.Has(deck.REVERSE) {
-		if g.PlayerAmount() != 2 {
-			g.Reverse()
-		} else {
-			jump = true
-		}
+	if g.CanBeChallenged() {
+		g.EndTurn(skips, CHALLENGE)
+	} else {
+		g.EndTurn(skips, CHOOSE_CARD)
 	}
-
-	// TODO: SKIPALL DISCARDALL etc
-
-	g.EndTurn(jump, nextState)
 }
 
 func (g *Game) DrawCard() {
 	g.logger.Trace().Msg("Drawing a card")
 
-	if g.HasPendingCatorce() {
-		p := g.GetPlayer(g.PlayerCatorce)
-		g.logger.Trace().Str("player_name", p.Name).Msg("There's a pending catorce!")
-		for i := 0; i < 4; i++ {
-			card := g.Deck.Draw()
-			p.AddCard(card)
-		}
-		g.PlayerCatorce = 0
-	}
+	g.ApplyCatorcePenalty()
+
+	p := g.CurrentPlayer()
 
 	if g.DrawCount == 0 {
 		card := g.Deck.Draw()
-		g.CurrentPlayer().AddCard(card)
+		p.AddCard(card)
+		g.LastDrawAmount = 1
+
+		// Keep drawing until the player can play something or the limit is reached
+		for g.Config.DrawUntilPlayable && g.LastDrawAmount < g.Config.DrawLimit &&
+			!card.CanPlayOnTop(g.GetCurrentCard(), false, g.Config.StackConfig) {
+			card = g.Deck.Draw()
+			p.AddCard(card)
+			g.LastDrawAmount += 1
+		}
+
+		g.logger.Trace().Int("amount", g.LastDrawAmount).Msg("Cards drawn")
+		p.CardsDrawn += g.LastDrawAmount
+		g.emit(CardsDrawn{Player: p, Amount: g.LastDrawAmount})
+
+		if g.LastDrawAmount > g.LargestDraw {
+			g.LargestDraw = g.LastDrawAmount
+		}
+
 		g.logger.Debug().Str("from", string(g.State)).Str("to", "DREW").Msg("Changing state")
 		g.State = DREW
 		return
@@ -265,31 +400,143 @@ func (g *Game) DrawCard() {
 
 	for i := 0; i < g.DrawCount; i++ {
 		card := g.Deck.Draw()
-		g.CurrentPlayer().AddCard(card)
+		p.AddCard(card)
 	}
 
+	p.CardsDrawn += g.DrawCount
+	g.emit(CardsDrawn{Player: p, Amount: g.DrawCount})
+	g.LastDrawAmount = g.DrawCount
 	g.DrawCount = 0
-	g.EndTurn(false, CHOOSE_CARD)
+	g.EndTurn(0, CHOOSE_CARD)
+}
+
+// ApplyCatorcePenalty gives the penalty cards to every player that didn't call catorce in time
+func (g *Game) ApplyCatorcePenalty() {
+	for _, p := range g.pendingCatorces() {
+		g.penalizeCatorce(p, nil)
+	}
+}
+
+// CatchCatorce applies the catorce penalty right away to every player catcher can catch, and returns them
+func (g *Game) CatchCatorce(catcher *Player) []*Player {
+	caught := g.catchable(catcher)
+
+	for _, p := range caught {
+		g.logger.Trace().Int("pid", catcher.ID).Int("caught", p.ID).Msg("Catorce caught")
+		catcher.Catches += 1
+		g.penalizeCatorce(p, catcher)
+	}
+
+	return caught
+}
+
+// CanCatch checks if by can catch anyone, the grace window to call catorce must be over
+func (g *Game) CanCatch(by *Player) bool {
+	return len(g.catchable(by)) > 0
+}
+
+// catchable returns the players other than by whose grace window to call catorce is over, in seat order
+func (g *Game) catchable(by *Player) []*Player {
+	return slices.DeleteFunc(g.pendingCatorces(), func(p *Player) bool {
+		return p == by || g.now().Sub(g.PendingCatorces[p.ID]) < g.Config.CatchGrace
+	})
+}
+
+// pendingCatorces returns the players that must call catorce, in seat order
+func (g *Game) pendingCatorces() []*Player {
+	pending := []*Player{}
+
+	for _, p := range g.Players {
+		if g.MustCallCatorce(p) {
+			pending = append(pending, p)
+		}
+	}
+
+	return pending
+}
+
+// penalizeCatorce gives the penalty cards to p for not calling catorce, by is who caught them, if anyone
+func (g *Game) penalizeCatorce(p *Player, by *Player) {
+	g.logger.Trace().Str("player_name", p.Name).Msg("There's a pending catorce!")
+	p.CatorcesMissed += 1
+
+	for i := 0; i < g.Config.CatorcePenalty; i++ {
+		card := g.Deck.Draw()
+		p.AddCard(card)
+	}
+	delete(g.PendingCatorces, p.ID)
+	g.emit(PenaltyApplied{Player: p, Amount: g.Config.CatorcePenalty, CaughtBy: by})
+}
+
+// CanBeChallenged checks if the current card can be challenged by the next player
+// Only wild draw cards can be challenged, and only if the chat allows it
+func (g *Game) CanBeChallenged() bool {
+	c := g.GetCurrentCard()
+	return g.Config.Challenge && c.Type.Has(deck.WILD) && c.Type.Has(deck.DRAW) && g.PreviousCard != nil
+}
+
+// Challenge resolves the current player's challenge of the last wild draw card
+// If whoever played it had a card matching the previous color, they draw the card's value instead and the
+// challenger plays, facing only the draws stacked before it. Otherwise the challenger draws two extra cards and loses the turn
+func (g *Game) Challenge() {
+	challenger := g.CurrentPlayer()
+	bluffer := g.GetPlayer(g.PreviousPlayer)
+	guilty := bluffer.HasColor(g.PreviousCard.Color)
+
+	// The bluffer is judged by the hand they played the +4 with, before any catorce penalty
+	g.ApplyCatorcePenalty()
+
+	g.logger.Trace().Int("challenger", challenger.ID).Int("bluffer", bluffer.ID).Bool("guilty", guilty).Msg("Resolving challenge")
+
+	if guilty {
+		challenger.ChallengesWon += 1
+		bluffer.ChallengesLost += 1
+
+		// Only the challenged card is on the bluffer, draws stacked before it are still pending for the challenger
+		amount := min(g.CurrentCard.Value, g.DrawCount)
+		for i := 0; i < amount; i++ {
+			bluffer.AddCard(g.Deck.Draw())
+		}
+
+		bluffer.CardsDrawn += amount
+		g.emit(ChallengeResolved{Challenger: challenger, Bluffer: bluffer, Guilty: true, Amount: amount})
+		g.emit(CardsDrawn{Player: bluffer, Amount: amount})
+		g.LastDrawAmount = amount
+		g.DrawCount -= amount
+
+		g.logger.Debug().Str("from", string(g.State)).Str("to", "CHOOSE_CARD").Msg("Changing state")
+		g.State = CHOOSE_CARD
+		return
+	}
+
+	challenger.ChallengesLost += 1
+	bluffer.ChallengesWon += 1
+
+	g.DrawCount += 2
+	g.emit(ChallengeResolved{Challenger: challenger, Bluffer: bluffer, Guilty: false, Amount: g.DrawCount})
+	g.DrawCard()
 }
 
-// EndTurn finishes the turn, returns true if the game is over
-func (g *Game) EndTurn(skip bool, nextState GameState) bool {
+// EndTurn finishes the turn, skipping the next skips players, returns true if the game is over
+func (g *Game) EndTurn(skips int, nextState GameState) bool {
 	g.Rounds += 1
 	g.logger.Trace().Int("pid", g.CurrentPlayer().ID).Int("rounds", g.Rounds).Msg("Ending turn")
 	if len(g.CurrentPlayer().Hand) == 0 {
 		g.logger.Trace().Int("pid", g.CurrentPlayer().ID).Msg("Player has 0 cards")
-		g.State = LOBBY
+		g.win()
 		return true
 	}
 
-	if len(g.CurrentPlayer().Hand) == 1 && g.CurrentCard.Type != deck.SWAP {
-		g.logger.Trace().Int("pid", g.CurrentPlayer().ID).Msg("Player has 1 card left, setting catorce")
-		g.PlayerCatorce = g.CurrentPlayer().ID
+	// Catorces for swapped hands are checked when swapping
+	swapping := g.State == CHOOSE_PLAYER || nextState == CHOOSE_PLAYER || slices.Contains(g.Decisions, CHOOSE_PLAYER)
+	if len(g.CurrentPlayer().Hand) == 1 && !swapping {
+		g.setCatorce(g.CurrentPlayer())
 	}
 
-	if nextState == CHOOSE_CARD {
+	if nextState == CHOOSE_CARD || nextState == CHALLENGE {
 		g.NextPlayer()
-		if skip {
+		for i := 0; i < skips; i++ {
+			g.emit(TurnSkipped{Player: g.CurrentPlayer()})
 			g.NextPlayer()
 		}
 	}
@@ -302,7 +549,119 @@ func (g *Game) EndTurn(skip bool, nextState GameState) bool {
 }
 
 func (g *Game) SwapHands(p1, p2 *Player) {
+	before := g.handSizes()
 	p1.Hand, p2.Hand = p2.Hand, p1.Hand
+	g.emit(HandsSwapped{From: p1, To: p2})
+	g.recheckCatorce(before)
+}
+
+// CanJumpIn checks if p can play c out of turn
+// Only a card identical to the current one (same color, type and value) can jump in
+func (g *Game) CanJumpIn(p *Player, c *deck.Card) bool {
+	if !g.Config.JumpIn || p == g.CurrentPlayer() {
+		return false
+	}
+
+	if g.State != CHOOSE_CARD && g.State != DREW {
+		return false
+	}
+
+	top := g.GetCurrentCard()
+	if c.IsSpecial() || c.Color != top.Color || c.Type != top.Type || c.Value != top.Value {
+		return false
+	}
+
+	// Pending draws can only be jumped on if they could be stacked
+	return g.DrawCounter() == 0 || c.CanPlayOnTop(top, true, g.Config.StackConfig)
+}
+
+// JumpIn makes p the current player, the turn order continues from them
+func (g *Game) JumpIn(p *Player) {
+	g.logger.Trace().Int("pid", p.ID).Msg("Player jumped in")
+
+	for g.CurrentPlayer() != p {
+		g.NextPlayer()
+	}
+}
+
+// RotateHands passes every hand to the next player in play direction
+func (g *Game) RotateHands() {
+	g.logger.Trace().Msg("Rotating hands")
+	before := g.handSizes()
+	last := g.Players[len(g.Players)-1].Hand
+
+	for i := len(g.Players) - 1; i > 0; i-- {
+		g.Players[i].Hand = g.Players[i-1].Hand
+	}
+
+	g.Players[0].Hand = last
+	g.emit(HandsRotated{})
+	g.recheckCatorce(before)
+}
+
+// handSizes maps every player to their current hand size
+func (g *Game) handSizes() map[int]int {
+	sizes := make(map[int]int, len(g.Players))
+
+	for _, p := range g.Players {
+		sizes[p.ID] = len(p.Hand)
+	}
+
+	return sizes
+}
+
+// recheckCatorce re-evaluates the pending catorces after hands changed owners
+// before holds the hand sizes prior to the change, players whose hand changed to a single card must call catorce
+func (g *Game) recheckCatorce(before map[int]int) {
+	for id := range g.PendingCatorces {
+		if p := g.GetPlayer(id); p == nil || len(p.Hand) != 1 {
+			g.logger.Trace().Int("pid", id).Msg("Hand changed, clearing catorce")
+			delete(g.PendingCatorces, id)
+		}
+	}
+
+	for _, p := range g.Players {
+		if len(p.Hand) == 1 && before[p.ID] != 1 {
+			g.setCatorce(p)
+		}
+	}
+}
+
+// setCatorce makes p one of the players that must call catorce
+func (g *Game) setCatorce(p *Player) {
+	if g.MustCallCatorce(p) {
+		return
+	}
+
+	if g.PendingCatorces == nil {
+		g.PendingCatorces = map[int]time.Time{}
+	}
+
+	g.logger.Trace().Int("pid", p.ID).Msg("Player has a single card, setting catorce")
+	g.PendingCatorces[p.ID] = g.now()
+	g.emit(CatorcePending{Player: p})
+}
+
+// win finishes the game with the current player as the winner
+func (g *Game) win() {
+	g.Winner = g.CurrentPlayer().ID
+	g.State = LOBBY
+	g.emit(GameWon{Player: g.CurrentPlayer(), Team: g.WinningTeam()})
+}
+
+// DiscardAll discards every card with color c from the current player's hand
+func (g *Game) DiscardAll(c deck.Color) {
+	p := g.CurrentPlayer()
+	g.logger.Trace().Int("pid", p.ID).Str("color", string(c)).Msg("Discarding all cards of color")
+
+	p.Hand = slices.DeleteFunc(p.Hand, func(card *deck.Card) bool {
+		if card.Color != c {
+			return false
+		}
+
+		g.Deck.Discard(card)
+		return true
+	})
 }
 
 func (g *Game) Reverse() {
@@ -312,14 +671,29 @@ func (g *Game) Reverse() {
 	g.Players = g.Players[1:]
 	slices.Reverse(g.Players)
 	g.Players = append([]*Player{p}, g.Players...)
+	g.emit(DirectionReversed{})
 }
 
+// ChooseColor sets the color of the current wild card and applies its effects that depend on it
 func (g *Game) ChooseColor(c deck.Color) {
 	// We change the card color to the chosen color, this only
 	// affects special cards, so we don't see it as they are always black
 	g.logger.Trace().Str("color", string(c)).Msg("Setting card color")
 	g.CurrentCard.SetColor(c)
-	g.EndTurn(false, CHOOSE_CARD)
+
+	for _, e := range effects {
+		if e.ColorChosen != nil && g.CurrentCard.Type.Has(e.Type) {
+			e.ColorChosen(g, g.CurrentCard, c)
+		}
+	}
+
+	g.advance()
+}
+
+// ChooseSwapTarget swaps the current player's hand with target's
+func (g *Game) ChooseSwapTarget(target *Player) {
+	g.SwapHands(g.CurrentPlayer(), target)
+	g.advance()
 }
 
 func (g *Game) NextPlayer() {
@@ -337,9 +711,33 @@ func (g *Game) GetDeck() *deck.Deck {
 	return g.Deck
 }
 
+// AssignCardIDs gives an ID to every card of the game that doesn't have one
+// Games saved before cards had IDs are migrated with it when loaded
+func (g *Game) AssignCardIDs() {
+	if g.Deck == nil {
+		return
+	}
+
+	cards := append(append([]*deck.Card{}, g.Deck.Cards...), g.Deck.Graveyard...)
+	for _, p := range g.Players {
+		cards = append(cards, p.Hand...)
+	}
+
+	if g.CurrentCard != nil {
+		cards = append(cards, g.CurrentCard)
+	}
+
+	for _, c := range cards {
+		if c.ID == 0 {
+			g.Deck.AssignID(c)
+		}
+	}
+}
+
 func (g *Game) ResetDeck() {
 	g.logger.Trace().Msg("Resetting deck")
 	g.Deck = deck.New(g.Config.DeckConfig, false)
+	g.Deck.SetSeed(g.Rand().Int63())
 }
 
 func (g *Game) GetState() GameState {
@@ -355,31 +753,88 @@ func (g *Game) ResetDrawCounter() {
 	g.DrawCount = 0
 }
 
-func (g *Game) CurrentCardSticker() *tb.Sticker {
-	return &tb.Sticker{
-		File: tb.File{FileID: g.GetCurrentCard().Sticker()},
+// now returns the current time, see clock
+func (g *Game) now() time.Time {
+	if g.clock != nil {
+		return g.clock()
 	}
+
+	return time.Now()
 }
 
+// HasPendingCatorce checks if any player must call catorce
 func (g *Game) HasPendingCatorce() bool {
-	return g.PlayerCatorce != 0
+	return len(g.PendingCatorces) > 0
+}
+
+// MustCallCatorce checks if p has a pending catorce
+func (g *Game) MustCallCatorce(p *Player) bool {
+	_, ok := g.PendingCatorces[p.ID]
+	return ok
 }
 
-func (g *Game) GameInfo() string {
-	var out strings.Builder
-	fmt.Fprintf(&out, "Jogador atual: %s \\[%d]\n", g.CurrentPlayer().NameWithMention(), len(g.CurrentPlayer().Hand))
-	fmt.Fprintf(&out, "Última carta: %s\n", g.GetCurrentCard().StringPretty())
-	fmt.Fprint(&out, "Próximos Jogadores:\n")
+// TurnDeadline returns when the current turn times out
+// Returns the zero time if the game isn't running or there's no timeout configured
+func (g *Game) TurnDeadline() time.Time {
+	if g.State == LOBBY || g.Config.TurnTimeout <= 0 {
+		return time.Time{}
+	}
+
+	return g.TurnStarted.Add(g.Config.TurnTimeout)
+}
 
-	for _, p := range g.PlayerList() {
-		if p == g.CurrentPlayer() {
-			continue
+// Timeout plays the current turn for an idle player through FireEvent
+// The player draws (and passes) when choosing a card or facing a challengeable card, a random color is chosen
+// on CHOOSE_COLOR and a random target on CHOOSE_PLAYER
+func (g *Game) Timeout() EventError {
+	p := g.CurrentPlayer()
+	g.logger.Trace().Int("pid", p.ID).Str("state", string(g.State)).Msg("Turn timed out")
+
+	// The timeout is journaled as a whole instead of the events the bot fired for the player
+	mark := g.drawMark()
+	snap := g.snapshot()
+	g.muted = true
+
+	var err EventError
+
+	switch g.State {
+	case CHOOSE_CARD:
+		err = g.FireEvent(&EvtDrawCard{Player: p})
+
+		if err == nil && g.State == DREW {
+			err = g.FireEvent(&EvtPass{Player: p})
 		}
 
-		fmt.Fprintf(&out, " • %s \\[%d]\n", p.Name, len(p.Hand))
+	case DREW:
+		err = g.FireEvent(&EvtPass{Player: p})
+
+	case CHALLENGE:
+		err = g.FireEvent(&EvtDrawCard{Player: p})
+
+	case CHOOSE_COLOR:
+		color := deck.PlayableColors[g.Rand().Intn(len(deck.PlayableColors))]
+		err = g.FireEvent(&EvtColorChosen{Player: p, Color: color})
+
+	case CHOOSE_PLAYER:
+		targets := g.Players[1:]
+		err = g.FireEvent(&EvtPlayerSwapChosen{Player: p, Target: targets[g.Rand().Intn(len(targets))].ID})
+
+	default:
+		err = ErrEventNotCovered
+	}
+
+	g.muted = false
+
+	if err != nil {
+		g.outcomes = nil
+		return err
 	}
 
-	fmt.Fprintf(&out, "Cartas na pilha: %d\n", g.Deck.Available())
+	p.TimeOuts += 1
+	g.addPlay(Play{Player: p.Name, Event: JournalTimeout}, mark)
+	g.pushHistory(snap)
+	g.record(JournalEntry{Event: JournalTimeout, Player: p.ID}, mark)
+	g.dispatch()
 
-	return out.String()
+	return nil
 }
@@ -0,0 +1,376 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/d-nery/catorce/pkg/deck"
+	"github.com/rs/zerolog"
+)
+
+// newTestGame starts a game with n players seated in join order, IDs 1 to n
+// The hands and the top card are replaced by the tests, so the game starts on a clean CHOOSE_CARD turn
+func newTestGame(t *testing.T, n int, config *Config) *Game {
+	t.Helper()
+
+	config.RandomOrder = false
+	g := New(1, zerolog.Nop(), config)
+
+	for i := 1; i <= n; i++ {
+		if err := g.FireEvent(&EvtAddPlayer{Player: NewPlayer(i, "Player", "")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := g.FireEvent(&EvtStartGame{Seed: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	g.State = CHOOSE_CARD
+	g.DrawCount = 0
+	g.Decisions = nil
+	g.PendingSkips = 0
+	for g.CurrentPlayer().ID != 1 {
+		g.NextPlayer()
+	}
+
+	g.CurrentCard = card(g, deck.RED, deck.NUMBER, 7)
+	return g
+}
+
+// card creates a card with a new ID from the game's deck
+func card(g *Game, color deck.Color, t deck.CardType, value int) *deck.Card {
+	c := deck.NewCard(color, t, value)
+	g.Deck.AssignID(c)
+	return c
+}
+
+func fire(t *testing.T, g *Game, evt interface{}) {
+	t.Helper()
+
+	if err := g.FireEvent(evt); err != nil {
+		t.Fatalf("%s: %v", EventName(evt), err)
+	}
+}
+
+// playWildDraw makes player 1 play a +4 in blue, leaving them with hand, so player 2 can challenge it
+func playWildDraw(t *testing.T, g *Game, hand ...*deck.Card) {
+	t.Helper()
+
+	p := g.GetPlayer(1)
+	wd4 := card(g, deck.BLACK, deck.WILD|deck.DRAW, 4)
+	p.Hand = append([]*deck.Card{wd4}, hand...)
+
+	fire(t, g, &EvtCardPlayed{Player: p, Card: wd4})
+	fire(t, g, &EvtColorChosen{Player: p, Color: deck.BLUE})
+
+	if g.State != CHALLENGE {
+		t.Fatalf("state is %s after a +4, want %s", g.State, CHALLENGE)
+	}
+}
+
+func TestChallenge(t *testing.T) {
+	tests := []struct {
+		name            string
+		hand            func(g *Game) []*deck.Card
+		skipCatorce     bool
+		stacked         int // Draws pending before the +4
+		guilty          bool
+		bluffer, victim int // Hand sizes after the challenge
+		pending         int // Draws left for the challenger
+	}{
+		{
+			name: "guilty",
+			hand: func(g *Game) []*deck.Card {
+				return []*deck.Card{card(g, deck.RED, deck.NUMBER, 5), card(g, deck.BLUE, deck.NUMBER, 3)}
+			},
+			guilty:  true,
+			bluffer: 2 + 4,
+			victim:  7,
+		},
+		{
+			name: "innocent",
+			hand: func(g *Game) []*deck.Card {
+				return []*deck.Card{card(g, deck.GREEN, deck.NUMBER, 5), card(g, deck.BLUE, deck.NUMBER, 3)}
+			},
+			guilty:  false,
+			bluffer: 2,
+			victim:  7 + 6,
+		},
+		{
+			name: "guilty with stacked draws",
+			hand: func(g *Game) []*deck.Card {
+				return []*deck.Card{card(g, deck.RED, deck.NUMBER, 5), card(g, deck.BLUE, deck.NUMBER, 3)}
+			},
+			stacked: 2,
+			guilty:  true,
+			bluffer: 2 + 4,
+			victim:  7,
+			pending: 2,
+		},
+		{
+			name: "innocent with stacked draws",
+			hand: func(g *Game) []*deck.Card {
+				return []*deck.Card{card(g, deck.GREEN, deck.NUMBER, 5), card(g, deck.BLUE, deck.NUMBER, 3)}
+			},
+			stacked: 2,
+			guilty:  false,
+			bluffer: 2,
+			victim:  7 + 2 + 6,
+		},
+		{
+			// The penalty cards are all red, but the +4 was played without any
+			name: "innocent with catorce penalty",
+			hand: func(g *Game) []*deck.Card {
+				return []*deck.Card{card(g, deck.GREEN, deck.NUMBER, 5)}
+			},
+			skipCatorce: true,
+			guilty:      false,
+			bluffer:     1 + 4,
+			victim:      7 + 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Challenge = true
+			g := newTestGame(t, 2, config)
+
+			red := []*deck.Card{}
+			for i := 0; i < g.Config.CatorcePenalty; i++ {
+				red = append(red, card(g, deck.RED, deck.NUMBER, i))
+			}
+			g.Deck.Cards = append(red, g.Deck.Cards...)
+
+			var resolved *ChallengeResolved
+			g.AddListener(func(g *Game, o Outcome) {
+				if o, ok := o.(ChallengeResolved); ok {
+					resolved = &o
+				}
+			})
+
+			playWildDraw(t, g, tt.hand(g)...)
+			g.DrawCount += tt.stacked
+			if tt.skipCatorce != g.HasPendingCatorce() {
+				t.Fatalf("pending catorce is %v, want %v", g.HasPendingCatorce(), tt.skipCatorce)
+			}
+
+			bluffer, victim := g.GetPlayer(1), g.GetPlayer(2)
+			fire(t, g, &EvtChallenge{Player: victim})
+
+			if resolved == nil || resolved.Guilty != tt.guilty {
+				t.Fatalf("challenge resolved as %+v, want guilty %v", resolved, tt.guilty)
+			}
+
+			if len(bluffer.Hand) != tt.bluffer || len(victim.Hand) != tt.victim {
+				t.Errorf("hand sizes are %d and %d, want %d and %d", len(bluffer.Hand), len(victim.Hand), tt.bluffer, tt.victim)
+			}
+
+			// A guilty bluffer lets the challenger play, otherwise the challenger loses the turn
+			want := bluffer
+			if tt.guilty {
+				want = victim
+			}
+
+			if g.State != CHOOSE_CARD || g.CurrentPlayer() != want {
+				t.Errorf("turn is %s for player %d, want %s for player %d", g.State, g.CurrentPlayer().ID, CHOOSE_CARD, want.ID)
+			}
+
+			if g.DrawCount != tt.pending {
+				t.Errorf("%d draws pending, want %d", g.DrawCount, tt.pending)
+			}
+		})
+	}
+}
+
+func TestWildSwapDecisions(t *testing.T) {
+	g := newTestGame(t, 3, DefaultConfig())
+	p1, p2, p3 := g.GetPlayer(1), g.GetPlayer(2), g.GetPlayer(3)
+
+	ws := card(g, deck.BLACK, deck.WILD|deck.SWAP, -1)
+	kept := []*deck.Card{card(g, deck.RED, deck.NUMBER, 5), card(g, deck.BLUE, deck.NUMBER, 3)}
+	p1.Hand = append([]*deck.Card{ws}, kept...)
+	other := append([]*deck.Card{}, p3.Hand...)
+
+	fire(t, g, &EvtCardPlayed{Player: p1, Card: ws})
+	if g.State != CHOOSE_COLOR || g.CurrentPlayer() != p1 {
+		t.Fatalf("turn is %s for player %d, want %s for player 1", g.State, g.CurrentPlayer().ID, CHOOSE_COLOR)
+	}
+
+	// The color can't be skipped
+	if err := g.FireEvent(&EvtPlayerSwapChosen{Player: p1, Target: p3.ID}); err == nil {
+		t.Fatal("swap target accepted before choosing the color")
+	}
+
+	fire(t, g, &EvtColorChosen{Player: p1, Color: deck.GREEN})
+	if g.State != CHOOSE_PLAYER || g.CurrentPlayer() != p1 {
+		t.Fatalf("turn is %s for player %d, want %s for player 1", g.State, g.CurrentPlayer().ID, CHOOSE_PLAYER)
+	}
+
+	fire(t, g, &EvtPlayerSwapChosen{Player: p1, Target: p3.ID})
+	if g.State != CHOOSE_CARD || g.CurrentPlayer() != p2 {
+		t.Fatalf("turn is %s for player %d, want %s for player 2", g.State, g.CurrentPlayer().ID, CHOOSE_CARD)
+	}
+
+	if g.CurrentCard != ws || ws.Color != deck.GREEN {
+		t.Errorf("top card is %s in %s, want the wild swap in green", g.CurrentCard, g.CurrentCard.Color)
+	}
+
+	if !sameCards(p1.Hand, other) || !sameCards(p3.Hand, kept) {
+		t.Errorf("hands weren't swapped: player 1 has %v, player 3 has %v", p1.Hand, p3.Hand)
+	}
+
+	if len(g.Decisions) != 0 || g.PendingSkips != 0 {
+		t.Errorf("decisions %v and skips %d left after the turn", g.Decisions, g.PendingSkips)
+	}
+}
+
+// sameCards checks if both hands have the same cards, in any order
+func sameCards(a, b []*deck.Card) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	ids := map[int]bool{}
+	for _, c := range a {
+		ids[c.ID] = true
+	}
+
+	for _, c := range b {
+		if !ids[c.ID] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestCatchCatorce(t *testing.T) {
+	g := newTestGame(t, 3, DefaultConfig())
+	p1, p2, p3 := g.GetPlayer(1), g.GetPlayer(2), g.GetPlayer(3)
+
+	now := time.Unix(0, 0)
+	g.clock = func() time.Time { return now }
+
+	p2.Hand = p2.Hand[:1]
+	g.setCatorce(p2)
+
+	now = now.Add(g.Config.CatchGrace / 2)
+	p3.Hand = p3.Hand[:1]
+	g.setCatorce(p3)
+
+	if err := g.FireEvent(&EvtCatchCatorce{Player: p1}); err != ErrCatchTooSoon {
+		t.Fatalf("catching inside the grace window returned %v, want %v", err, ErrCatchTooSoon)
+	}
+
+	// Only the first one is out of the grace window
+	now = now.Add(g.Config.CatchGrace / 2)
+	evt := &EvtCatchCatorce{Player: p1}
+	fire(t, g, evt)
+
+	if len(evt.Caught) != 1 || evt.Caught[0] != p2 {
+		t.Fatalf("caught %v, want player 2", PlayerNames(evt.Caught))
+	}
+
+	if len(p2.Hand) != 1+g.Config.CatorcePenalty || g.MustCallCatorce(p2) {
+		t.Errorf("player 2 has %d cards and pending catorce %v after being caught", len(p2.Hand), g.MustCallCatorce(p2))
+	}
+
+	if !g.MustCallCatorce(p3) {
+		t.Fatal("player 3 catorce was cleared by catching player 2")
+	}
+
+	if err := g.FireEvent(&EvtCatchCatorce{Player: p3}); err != ErrWrongPlayer {
+		t.Errorf("catching yourself returned %v, want %v", err, ErrWrongPlayer)
+	}
+
+	fire(t, g, &EvtCatorce{Player: p3})
+	if g.HasPendingCatorce() || len(p3.Hand) != 1 {
+		t.Errorf("player 3 has %d cards and pending catorces are %v after calling catorce", len(p3.Hand), g.PendingCatorces)
+	}
+}
+
+func TestSwapAllCatorces(t *testing.T) {
+	g := newTestGame(t, 4, DefaultConfig())
+	p1, p2, p3, p4 := g.GetPlayer(1), g.GetPlayer(2), g.GetPlayer(3), g.GetPlayer(4)
+
+	// Hands of 1, 3, 1 and 3 cards after playing, every hand of one card goes to someone that had more
+	sa := card(g, deck.RED, deck.SWAPALL, -1)
+	p1.Hand = []*deck.Card{sa, p1.Hand[0]}
+	p2.Hand = p2.Hand[:3]
+	p3.Hand = p3.Hand[:1]
+	p4.Hand = p4.Hand[:3]
+
+	fire(t, g, &EvtCardPlayed{Player: p1, Card: sa})
+
+	for _, p := range g.Players {
+		want := p == p2 || p == p4
+		if g.MustCallCatorce(p) != want {
+			t.Errorf("player %d with %d cards has pending catorce %v, want %v", p.ID, len(p.Hand), g.MustCallCatorce(p), want)
+		}
+	}
+
+	if g.State != CHOOSE_CARD || g.CurrentPlayer() != p2 {
+		t.Fatalf("turn is %s for player %d, want %s for player 2", g.State, g.CurrentPlayer().ID, CHOOSE_CARD)
+	}
+
+	// Player 4 didn't call it in time, the next move penalizes them
+	fire(t, g, &EvtCatorce{Player: p2})
+	fire(t, g, &EvtDrawCard{Player: p2})
+
+	if len(p4.Hand) != 1+g.Config.CatorcePenalty || g.HasPendingCatorce() {
+		t.Errorf("player 4 has %d cards and pending catorces are %v, want %d cards and none", len(p4.Hand), g.PendingCatorces, 1+g.Config.CatorcePenalty)
+	}
+}
+
+// deal starts a game between n players with seed, players are seated randomly
+func deal(t *testing.T, n int, seed int64) *Game {
+	t.Helper()
+
+	g := New(1, zerolog.Nop(), DefaultConfig())
+	for i := 1; i <= n; i++ {
+		fire(t, g, &EvtAddPlayer{Player: NewPlayer(i, "Player", "")})
+	}
+
+	fire(t, g, &EvtStartGame{Seed: seed})
+	return g
+}
+
+// table lists the seats, hands, top card and deck of g
+func table(g *Game) []string {
+	out := []string{g.CurrentCard.String()}
+
+	for _, p := range g.Players {
+		out = append(out, fmt.Sprint(p.ID))
+		for _, c := range p.Hand {
+			out = append(out, c.String())
+		}
+	}
+
+	for _, c := range g.Deck.Cards {
+		out = append(out, c.String())
+	}
+
+	return out
+}
+
+func TestSeedReplaysDeal(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		g := deal(t, 5, seed)
+
+		if g.Seed != seed {
+			t.Errorf("game seed is %d, want %d", g.Seed, seed)
+		}
+
+		if !slices.Equal(table(g), table(deal(t, 5, seed))) {
+			t.Errorf("seed %d dealt two different games", seed)
+		}
+
+		if slices.Equal(table(g), table(deal(t, 5, seed+1000))) {
+			t.Errorf("seeds %d and %d dealt the same game", seed, seed+1000)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// edge is an arrow of the state diagram, with every event that goes through it
+type edge struct {
+	from, to GameState
+	events   []string
+}
+
+// edges groups the transitions by origin and destination, in table order
+func edges(ts []Transition) []*edge {
+	var es []*edge
+	index := map[[2]GameState]*edge{}
+
+	for _, t := range ts {
+		for _, to := range t.To {
+			key := [2]GameState{t.From, to}
+
+			e, ok := index[key]
+			if !ok {
+				e = &edge{from: t.From, to: to}
+				index[key] = e
+				es = append(es, e)
+			}
+
+			if label := eventLabel(t.Event); !slices.Contains(e.events, label) {
+				e.events = append(e.events, label)
+			}
+		}
+	}
+
+	return es
+}
+
+// Graphviz renders the state machine as a Graphviz dot graph
+func Graphviz() string {
+	var out strings.Builder
+
+	fmt.Fprintln(&out, "digraph catorce {")
+	fmt.Fprintln(&out, "\trankdir=LR;")
+	fmt.Fprintln(&out, "\tnode [shape=box, style=rounded];")
+	fmt.Fprintln(&out, "\tstart [shape=point];")
+	fmt.Fprintf(&out, "\tstart -> %s;\n", LOBBY)
+
+	for _, e := range edges(Transitions) {
+		fmt.Fprintf(&out, "\t%s -> %s [label=\"%s\"];\n", e.from, e.to, strings.Join(e.events, "\\n"))
+	}
+
+	fmt.Fprintln(&out, "}")
+	return out.String()
+}
+
+// Mermaid renders the state machine as a Mermaid state diagram
+func Mermaid() string {
+	var out strings.Builder
+
+	fmt.Fprintln(&out, "stateDiagram-v2")
+	fmt.Fprintf(&out, "    [*] --> %s\n", LOBBY)
+
+	for _, e := range edges(Transitions) {
+		fmt.Fprintf(&out, "    %s --> %s: %s\n", e.from, e.to, strings.Join(e.events, ", "))
+	}
+
+	return out.String()
+}
@@ -0,0 +1,342 @@
+package game
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/d-nery/catorce/pkg/deck"
+	"github.com/rs/zerolog"
+)
+
+// Journal entries that aren't events
+const (
+	JournalHeader  = "Game"    // First entry, with the chat and initial config
+	JournalConfig  = "Config"  // The config was changed
+	JournalTimeout = "Timeout" // The current player timed out, the bot played for them
+	JournalUndo    = "Undo"    // The last move was undone
+	JournalEnd     = "End"     // The game is over, either finished or killed
+)
+
+// Possible journal errors
+var (
+	ErrJournalHeader   = errors.New("journal: missing header")
+	ErrJournalMismatch = errors.New("journal: replayed state doesn't match the journal")
+)
+
+// JournalEntry is a line in the game journal, either an accepted event with its outcome or a game change
+// Only the fields that make sense for the entry are set
+type JournalEntry struct {
+	Time  time.Time
+	Event string
+
+	// Event fields
+	Player   int        `json:",omitempty"`
+	Name     string     `json:",omitempty"`
+	Username string     `json:",omitempty"`
+	Strategy string     `json:",omitempty"`
+	Team     int        `json:",omitempty"`
+	Card     string     `json:",omitempty"`
+	CardID   int        `json:",omitempty"` // Tells identical cards apart, see deck.Card.ID
+	Color    deck.Color `json:",omitempty"`
+	Target   int        `json:",omitempty"`
+	Seed     int64      `json:",omitempty"`
+
+	// Header and config fields
+	Chat   int64   `json:",omitempty"`
+	Config *Config `json:",omitempty"`
+
+	// Outcome
+	State   GameState   `json:",omitempty"`
+	Current string      `json:",omitempty"` // Top card
+	Drawn   []string    `json:",omitempty"` // Cards drawn from the deck by the event
+	Hands   map[int]int `json:",omitempty"` // Hand size of every player
+	Rand    uint64      `json:",omitempty"` // Game random source state, so a replay consumes it the same way
+}
+
+// OpenJournal starts appending the game journal to the file at path, creating it if needed
+// New journals start with a header entry
+func (g *Game) OpenJournal(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	g.logger.Trace().Str("path", path).Msg("Opening journal")
+	g.JournalPath = path
+	g.journal = f
+
+	if info.Size() == 0 {
+		g.record(JournalEntry{Event: JournalHeader, Chat: g.Chat, Config: g.Config}, 0)
+	}
+
+	return nil
+}
+
+// CloseJournal ends the journal and closes its file
+func (g *Game) CloseJournal() {
+	if g.journal == nil {
+		return
+	}
+
+	g.record(JournalEntry{Event: JournalEnd}, g.drawMark())
+	g.journal.Close()
+	g.journal = nil
+}
+
+// drawMark marks the current position of the deck log, so the cards drawn afterwards can be recorded
+func (g *Game) drawMark() int {
+	if g.Deck == nil {
+		return 0
+	}
+
+	return len(g.Deck.Log)
+}
+
+// drawnSince returns the cards drawn from the deck since mark
+func (g *Game) drawnSince(mark int) []string {
+	if g.Deck == nil {
+		return nil
+	}
+
+	// The log is reset when the deck is committed at start
+	if mark > len(g.Deck.Log) {
+		mark = 0
+	}
+
+	var drawn []string
+	for _, de := range g.Deck.Log[mark:] {
+		if de.Draw != "" {
+			drawn = append(drawn, de.Draw)
+		}
+	}
+
+	return drawn
+}
+
+// record appends an entry to the journal, filling the outcome with the current game state
+// mark is the deck log position before the entry, see drawMark
+func (g *Game) record(e JournalEntry, mark int) {
+	if g.journal == nil || g.muted {
+		return
+	}
+
+	e.Time = time.Now()
+	e.State = g.State
+
+	if g.CurrentCard != nil {
+		e.Current = g.CurrentCard.String()
+	}
+
+	e.Drawn = g.drawnSince(mark)
+	e.Hands = g.handSizes()
+
+	if g.Source != nil {
+		e.Rand = g.Source.State
+	}
+
+	body, err := json.Marshal(e)
+	if err == nil {
+		_, err = g.journal.Write(append(body, '\n'))
+	}
+
+	if err != nil {
+		g.logger.Error().Err(err).Str("event", e.Event).Msg("Failed to write journal")
+	}
+}
+
+// journalEntry creates the journal entry for an event
+func journalEntry(evt interface{}) JournalEntry {
+	e := JournalEntry{Event: EventName(evt)}
+
+	switch evt := evt.(type) {
+	case *EvtStartGame:
+		e.Seed = evt.Seed
+	case *EvtCatorce:
+		e.Player = evt.Player.ID
+	case *EvtCatchCatorce:
+		e.Player = evt.Player.ID
+	case *EvtAddPlayer:
+		e.Player, e.Name, e.Username = evt.Player.ID, evt.Player.Name, evt.Player.Username
+		e.Strategy = evt.Player.Strategy
+	case *EvtChooseTeam:
+		e.Player, e.Team = evt.Player.ID, evt.Team
+	case *EvtRemovePlayer:
+		e.Player = evt.Player.ID
+	case *EvtCardPlayed:
+		e.Player, e.Card, e.CardID = evt.Player.ID, evt.Card.String(), evt.Card.ID
+	case *EvtColorChosen:
+		e.Player, e.Color = evt.Player.ID, evt.Color
+	case *EvtPlayerSwapChosen:
+		e.Player, e.Target = evt.Player.ID, evt.Target
+	case *EvtDrawCard:
+		e.Player = evt.Player.ID
+	case *EvtChallenge:
+		e.Player = evt.Player.ID
+	case *EvtPass:
+		e.Player = evt.Player.ID
+	}
+
+	return e
+}
+
+// event recreates the event of a journal entry for the game
+func (e *JournalEntry) event(g *Game) (interface{}, error) {
+	if e.Event == "EvtStartGame" {
+		return &EvtStartGame{Seed: e.Seed}, nil
+	}
+
+	if e.Event == "EvtAddPlayer" {
+		p := NewPlayer(e.Player, e.Name, e.Username)
+		p.Strategy = e.Strategy
+		return &EvtAddPlayer{Player: p}, nil
+	}
+
+	p := g.GetPlayer(e.Player)
+	if p == nil {
+		return nil, fmt.Errorf("journal: %s: player %d not found", e.Event, e.Player)
+	}
+
+	switch e.Event {
+	case "EvtCatorce":
+		return &EvtCatorce{Player: p}, nil
+	case "EvtCatchCatorce":
+		return &EvtCatchCatorce{Player: p}, nil
+	case "EvtChooseTeam":
+		return &EvtChooseTeam{Player: p, Team: e.Team}, nil
+	case "EvtRemovePlayer":
+		return &EvtRemovePlayer{Player: p}, nil
+	case "EvtCardPlayed":
+		// Journals written before cards had IDs only have the card name
+		for _, c := range p.Hand {
+			if (e.CardID != 0 && c.ID == e.CardID) || (e.CardID == 0 && c.String() == e.Card) {
+				return &EvtCardPlayed{Player: p, Card: c}, nil
+			}
+		}
+
+		return nil, fmt.Errorf("journal: %s: player %d doesn't have %s", e.Event, e.Player, e.Card)
+	case "EvtColorChosen":
+		return &EvtColorChosen{Player: p, Color: e.Color}, nil
+	case "EvtPlayerSwapChosen":
+		return &EvtPlayerSwapChosen{Player: p, Target: e.Target}, nil
+	case "EvtDrawCard":
+		return &EvtDrawCard{Player: p}, nil
+	case "EvtChallenge":
+		return &EvtChallenge{Player: p}, nil
+	case "EvtPass":
+		return &EvtPass{Player: p}, nil
+	}
+
+	return nil, fmt.Errorf("journal: unknown entry %q", e.Event)
+}
+
+// check compares the game with the outcome recorded in the entry, so a replay stops where it diverges
+// Entries written before hand sizes were journaled only have the state and top card
+func (e *JournalEntry) check(g *Game) error {
+	if g.State != e.State {
+		return fmt.Errorf("%w: state is %s, journal has %s", ErrJournalMismatch, g.State, e.State)
+	}
+
+	current := ""
+	if g.CurrentCard != nil {
+		current = g.CurrentCard.String()
+	}
+
+	if current != e.Current {
+		return fmt.Errorf("%w: top card is %s, journal has %s", ErrJournalMismatch, current, e.Current)
+	}
+
+	if e.Hands != nil && !maps.Equal(g.handSizes(), e.Hands) {
+		return fmt.Errorf("%w: hand sizes are %v, journal has %v", ErrJournalMismatch, g.handSizes(), e.Hands)
+	}
+
+	return nil
+}
+
+// Replay rebuilds a game from its journal, move by move
+// ended reports if the journal was closed, i.e. the game is over
+func Replay(r io.Reader, logger zerolog.Logger) (g *Game, ended bool, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		var e JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, false, fmt.Errorf("journal: line %d: %w", line, err)
+		}
+
+		// Time checks, like the catorce grace window, see the time of the entry
+		clock := func() time.Time { return e.Time }
+
+		if g == nil {
+			if e.Event != JournalHeader || e.Config == nil {
+				return nil, false, ErrJournalHeader
+			}
+
+			g = New(e.Chat, logger, e.Config)
+			g.clock = clock
+			continue
+		}
+
+		g.clock = clock
+
+		switch e.Event {
+		case JournalConfig:
+			g.SetConfig(e.Config)
+			continue
+		case JournalEnd:
+			ended = true
+			continue
+		case JournalTimeout:
+			err = g.Timeout()
+		case JournalUndo:
+			err = g.Undo()
+		default:
+			var evt interface{}
+			if evt, err = e.event(g); err != nil {
+				return nil, false, fmt.Errorf("journal: line %d: %w", line, err)
+			}
+
+			err = g.FireEvent(evt)
+		}
+
+		if err != nil {
+			return nil, false, fmt.Errorf("journal: line %d: %s: %w", line, e.Event, err)
+		}
+
+		if err := e.check(g); err != nil {
+			return nil, false, fmt.Errorf("journal: line %d: %w", line, err)
+		}
+
+		if g.Source != nil && e.Rand != 0 {
+			g.Source.State = e.Rand
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, false, err
+	}
+
+	if g == nil {
+		return nil, false, ErrJournalHeader
+	}
+
+	g.clock = nil
+	return g, ended, nil
+}
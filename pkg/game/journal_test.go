@@ -0,0 +1,128 @@
+package game_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/d-nery/catorce/pkg/ai"
+	"github.com/d-nery/catorce/pkg/deck"
+	"github.com/d-nery/catorce/pkg/game"
+	"github.com/rs/zerolog"
+)
+
+// playJournaled plays a game between computers with the journal open, mixing in timeouts, undos and a player leaving
+func playJournaled(t *testing.T, path string, seed int64) *game.Game {
+	t.Helper()
+
+	config := game.DefaultConfig()
+	config.Challenge = true
+	config.SevenO = true
+	config.DeckConfig.SetColoredAmount(deck.SWAPALL, -1, 1)
+	config.DeckConfig.SetColoredAmount(deck.DISCARDALL, -1, 1)
+	config.DeckConfig.SetAmount(deck.BLACK, deck.WILD|deck.SWAP, -1, 2)
+
+	g := game.New(1, zerolog.Nop(), config)
+	if err := g.OpenJournal(path); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, strategy := range []string{"facil", "dificil", "facil", "dificil"} {
+		p := game.NewPlayer(-(i + 1), "Robô", "")
+		p.Strategy = strategy
+
+		if err := g.FireEvent(&game.EvtAddPlayer{Player: p}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := g.FireEvent(&game.EvtStartGame{Seed: seed}); err != nil {
+		t.Fatal(err)
+	}
+
+	for move := 1; move < 500 && g.GetState() != game.LOBBY; move++ {
+		var err error
+
+		switch {
+		case move == 40:
+			err = g.FireEvent(&game.EvtRemovePlayer{Player: g.Players[1]})
+		case move%11 == 0:
+			err = g.Timeout()
+		case move%17 == 0 && g.CanUndo():
+			err = g.Undo()
+		default:
+			_, err = ai.Move(g)
+		}
+
+		if err != nil {
+			t.Fatalf("seed %d, move %d: %v", seed, move, err)
+		}
+	}
+
+	return g
+}
+
+// ids lists the IDs of cards, in order
+func ids(cards []*deck.Card) []int {
+	out := make([]int, 0, len(cards))
+	for _, c := range cards {
+		out = append(out, c.ID)
+	}
+
+	return out
+}
+
+func TestJournalRoundTrip(t *testing.T) {
+	for seed := int64(1); seed <= 30; seed++ {
+		path := filepath.Join(t.TempDir(), "journal.jsonl")
+		live := playJournaled(t, path, seed)
+		live.CloseJournal()
+
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		replayed, ended, err := game.Replay(f, zerolog.Nop())
+		f.Close()
+
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+
+		if !ended {
+			t.Errorf("seed %d: replayed journal isn't ended", seed)
+		}
+
+		if replayed.State != live.State || replayed.Winner != live.Winner {
+			t.Errorf("seed %d: replay is %s won by %d, live game is %s won by %d",
+				seed, replayed.State, replayed.Winner, live.State, live.Winner)
+		}
+
+		if a, b := replayed.CurrentCard, live.CurrentCard; a.ID != b.ID || a.Color != b.Color {
+			t.Errorf("seed %d: replay top card is %d %s, live game has %d %s", seed, a.ID, a, b.ID, b)
+		}
+
+		if len(replayed.Players) != len(live.Players) {
+			t.Fatalf("seed %d: replay has %d players, live game has %d", seed, len(replayed.Players), len(live.Players))
+		}
+
+		for i, p := range live.Players {
+			r := replayed.Players[i]
+
+			if r.ID != p.ID || r.Strategy != p.Strategy {
+				t.Errorf("seed %d: seat %d is %d (%s) in the replay, %d (%s) live", seed, i, r.ID, r.Strategy, p.ID, p.Strategy)
+			}
+
+			if !slices.Equal(ids(r.Hand), ids(p.Hand)) {
+				t.Errorf("seed %d: player %d hand is %v in the replay, %v live", seed, p.ID, ids(r.Hand), ids(p.Hand))
+			}
+		}
+
+		if !slices.Equal(ids(replayed.Deck.Cards), ids(live.Deck.Cards)) ||
+			!slices.Equal(ids(replayed.Deck.Graveyard), ids(live.Deck.Graveyard)) {
+			t.Errorf("seed %d: replay deck differs from the live one", seed)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package game
+
+import (
+	"sort"
+)
+
+// Match holds the points of consecutive games in a chat
+// The winner of each game scores the points left on everyone else's hands, in team mode every member
+// of the winning team scores the points left on the other team's hands.
+// The match is over when someone reaches the target
+type Match struct {
+	Target int
+	Games  int
+	Scores map[int]int    // Maps players to their accumulated points
+	Names  map[int]string // Maps players to their names, as they may leave between games
+}
+
+// NewMatch creates a new match that ends when someone reaches target points
+func NewMatch(target int) *Match {
+	return &Match{
+		Target: target,
+		Games:  0,
+		Scores: make(map[int]int),
+		Names:  make(map[int]string),
+	}
+}
+
+// AddGame adds the result of a finished game to the match
+func (m *Match) AddGame(g *Game) {
+	m.Games += 1
+
+	for _, p := range g.PlayerList() {
+		m.Names[p.ID] = p.Name
+
+		if _, ok := m.Scores[p.ID]; !ok {
+			m.Scores[p.ID] = 0
+		}
+	}
+
+	if g.Winner == 0 {
+		return
+	}
+
+	if team := g.WinningTeam(); team != NO_TEAM {
+		rival := TEAM_A
+		if team == TEAM_A {
+			rival = TEAM_B
+		}
+
+		points := g.TeamPoints(rival)
+		for _, p := range g.TeamMembers(team) {
+			m.Scores[p.ID] += points
+		}
+
+		return
+	}
+
+	points := 0
+	for _, p := range g.PlayerList() {
+		if p.ID != g.Winner {
+			points += p.CurrentHandPoints(g.Config.Scores)
+		}
+	}
+
+	m.Scores[g.Winner] += points
+}
+
+// Standings returns the players sorted by points, higher first
+func (m *Match) Standings() []int {
+	ids := make([]int, 0, len(m.Scores))
+
+	for id := range m.Scores {
+		ids = append(ids, id)
+	}
+
+	sort.SliceStable(ids, func(i, j int) bool {
+		if m.Scores[ids[i]] == m.Scores[ids[j]] {
+			return ids[i] < ids[j]
+		}
+
+		return m.Scores[ids[i]] > m.Scores[ids[j]]
+	})
+
+	return ids
+}
+
+// Winner returns the player that reached the target, 0 if the match isn't over
+// In team mode the whole winning team reaches it together, see Winners
+func (m *Match) Winner() int {
+	if winners := m.Winners(); len(winners) > 0 {
+		return winners[0]
+	}
+
+	return 0
+}
+
+// Winners returns every player that reached the target, in standings order
+func (m *Match) Winners() []int {
+	winners := []int{}
+
+	for _, id := range m.Standings() {
+		if m.Scores[id] >= m.Target {
+			winners = append(winners, id)
+		}
+	}
+
+	return winners
+}
@@ -0,0 +1,97 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/d-nery/catorce/pkg/deck"
+)
+
+// finish ends the game with winner, players are given hands by ID
+func finish(g *Game, winner int, hands map[int][]*deck.Card) {
+	for _, p := range g.Players {
+		p.Hand = hands[p.ID]
+	}
+
+	g.Winner = winner
+	g.State = LOBBY
+}
+
+func TestMatch(t *testing.T) {
+	g := newTestGame(t, 3, DefaultConfig())
+	m := NewMatch(50)
+
+	for i := 0; i < 2; i++ {
+		if m.Winner() != 0 {
+			t.Fatalf("match is over after %d games with %v", m.Games, m.Scores)
+		}
+
+		finish(g, 1, map[int][]*deck.Card{
+			2: {card(g, deck.RED, deck.NUMBER, 5)},
+			3: {card(g, deck.RED, deck.DRAW, 2)},
+		})
+		m.AddGame(g)
+	}
+
+	want := map[int]int{1: 50, 2: 0, 3: 0}
+	for id, points := range want {
+		if m.Scores[id] != points {
+			t.Errorf("player %d has %d points, want %d", id, m.Scores[id], points)
+		}
+	}
+
+	if m.Winner() != 1 || m.Games != 2 {
+		t.Errorf("match winner is %d after %d games, want player 1 after 2", m.Winner(), m.Games)
+	}
+}
+
+func TestTeamMatch(t *testing.T) {
+	config := DefaultConfig()
+	config.TeamMode = true
+	g := newTestGame(t, 4, config)
+	m := NewMatch(30)
+
+	winner := g.GetPlayer(1)
+	rivals := TEAM_A
+	if winner.Team == TEAM_A {
+		rivals = TEAM_B
+	}
+
+	// Points left on the winner's teammates don't count
+	hands := map[int][]*deck.Card{}
+	for _, p := range g.Players {
+		hands[p.ID] = []*deck.Card{card(g, deck.RED, deck.NUMBER, 9)}
+	}
+	hands[winner.ID] = nil
+	for _, p := range g.TeamMembers(rivals) {
+		hands[p.ID] = []*deck.Card{card(g, deck.RED, deck.NUMBER, 5), card(g, deck.RED, deck.DRAW, 2)}
+	}
+
+	finish(g, winner.ID, hands)
+	m.AddGame(g)
+
+	for _, p := range g.Players {
+		want := 0
+		if p.Team == winner.Team {
+			want = 2 * 25
+		}
+
+		if m.Scores[p.ID] != want {
+			t.Errorf("player %d in team %d has %d points, want %d", p.ID, p.Team, m.Scores[p.ID], want)
+		}
+	}
+
+	if winners := m.Winners(); len(winners) != 2 {
+		t.Errorf("match winners are %v, want the winning team", winners)
+	}
+}
+
+func TestTeamLateJoin(t *testing.T) {
+	config := DefaultConfig()
+	config.TeamMode = true
+	config.LateJoin = true
+	g := newTestGame(t, 4, config)
+
+	if err := g.FireEvent(&EvtAddPlayer{Player: NewPlayer(5, "Player", "")}); err != ErrEventNotCovered {
+		t.Errorf("late join in team mode returned %v, want %v", err, ErrEventNotCovered)
+	}
+}
@@ -0,0 +1,123 @@
+package game
+
+import "github.com/d-nery/catorce/pkg/deck"
+
+// Outcome is something that happened in the game because of an accepted event
+// Listeners receive outcomes after the event is fully applied, so the game already shows its result
+type Outcome interface {
+	outcome()
+}
+
+// CardPlayed is sent when a player puts a card on the table
+type CardPlayed struct {
+	Player   *Player
+	Card     *deck.Card
+	JumpedIn bool // Played out of turn, see CanJumpIn
+}
+
+// CardsDrawn is sent when a player draws cards, either by choice, pending draws or a lost challenge
+type CardsDrawn struct {
+	Player *Player
+	Amount int
+}
+
+// PenaltyApplied is sent when a player gets cards for not calling catorce in time
+// CaughtBy is the player that caught them, nil if the penalty came with the next move
+type PenaltyApplied struct {
+	Player   *Player
+	Amount   int
+	CaughtBy *Player
+}
+
+// ChallengeResolved is sent when a wild draw card is challenged
+// If Guilty, the bluffer drew Amount cards, otherwise the challenger did
+type ChallengeResolved struct {
+	Challenger *Player
+	Bluffer    *Player
+	Guilty     bool
+	Amount     int
+}
+
+// DirectionReversed is sent when the play direction changes
+type DirectionReversed struct{}
+
+// HandsSwapped is sent when two players exchange hands
+type HandsSwapped struct {
+	From *Player
+	To   *Player
+}
+
+// HandsRotated is sent when every hand is passed to the next player
+type HandsRotated struct{}
+
+// TurnSkipped is sent for every player that loses their turn
+type TurnSkipped struct {
+	Player *Player
+}
+
+// RouletteSpun is sent when a roulette card makes Player draw Amount cards until getting Color
+type RouletteSpun struct {
+	Player *Player
+	Color  deck.Color
+	Amount int
+}
+
+// CatorcePending is sent when a player is left with a single card and must call catorce
+type CatorcePending struct {
+	Player *Player
+}
+
+// PlayerLeft is sent when a player leaves a running game, Points is what was left in their hand
+type PlayerLeft struct {
+	Player *Player
+	Points int
+}
+
+// GameWon is sent when the game is over, Team is NO_TEAM outside team mode
+type GameWon struct {
+	Player *Player
+	Team   int
+}
+
+func (CardPlayed) outcome()        {}
+func (CardsDrawn) outcome()        {}
+func (PenaltyApplied) outcome()    {}
+func (ChallengeResolved) outcome() {}
+func (DirectionReversed) outcome() {}
+func (HandsSwapped) outcome()      {}
+func (HandsRotated) outcome()      {}
+func (TurnSkipped) outcome()       {}
+func (RouletteSpun) outcome()      {}
+func (CatorcePending) outcome()    {}
+func (PlayerLeft) outcome()        {}
+func (GameWon) outcome()           {}
+
+// Listener reacts to game outcomes, it's called with the game in the same goroutine that fired the event
+type Listener func(g *Game, o Outcome)
+
+// AddListener registers l to receive every outcome of the game
+// Listeners aren't persisted, they must be added again when a game is loaded
+func (g *Game) AddListener(l Listener) {
+	g.listeners = append(g.listeners, l)
+}
+
+// emit queues an outcome, they are dispatched once the event is over
+func (g *Game) emit(o Outcome) {
+	if len(g.listeners) == 0 {
+		return
+	}
+
+	g.outcomes = append(g.outcomes, o)
+}
+
+// dispatch sends the queued outcomes to every listener
+func (g *Game) dispatch() {
+	outcomes := g.outcomes
+	g.outcomes = nil
+
+	for _, o := range outcomes {
+		for _, l := range g.listeners {
+			l(g, o)
+		}
+	}
+}
@@ -4,8 +4,6 @@ import (
 	"fmt"
 	"time"
 
-	tb "gopkg.in/tucnak/telebot.v2"
-
 	"github.com/d-nery/catorce/pkg/deck"
 )
 
@@ -14,19 +12,28 @@ type Player struct {
 	Name     string
 	Username string
 	Hand     []*deck.Card
+	Team     int
+	Strategy string // Strategy of computer-controlled players, see IsAI
 
 	// Current game stats, are added to overall when game is over
 	CatorcesCalled int
 	CatorcesMissed int
+	Catches        int // Players caught without calling catorce
 	CardsPlayed    int
+	CardsDrawn     int
+	TimeOuts       int
+	ChallengesWon  int
+	ChallengesLost int
 	AvgRespTime    time.Duration
 }
 
-func NewPlayer(id int, user *tb.User) *Player {
+// NewPlayer creates a player with an empty hand
+// Username is optional, it's only used by frontends to mention the player
+func NewPlayer(id int, name, username string) *Player {
 	return &Player{
 		ID:       id,
-		Name:     user.FirstName,
-		Username: user.Username,
+		Name:     name,
+		Username: username,
 		Hand:     make([]*deck.Card, 0, 7),
 	}
 }
@@ -45,12 +52,26 @@ func (p *Player) RemoveCard(c *deck.Card) {
 	}
 }
 
-func (p *Player) NameWithMention() string {
-	if p.Username == "" {
-		return fmt.Sprintf("[%s](tg://user?id=%d)", p.Name, p.ID)
+// HasColor checks if the player has a non special card with color c
+func (p *Player) HasColor(c deck.Color) bool {
+	for _, crd := range p.Hand {
+		if !crd.IsSpecial() && crd.Color == c {
+			return true
+		}
 	}
 
-	return fmt.Sprintf("*%s* (@%s)", p.Name, p.Username)
+	return false
+}
+
+// IsAI checks if id belongs to a computer-controlled player
+// They have negative IDs, so they never clash with telegram users
+func IsAI(id int) bool {
+	return id < 0
+}
+
+// IsAI checks if the player is computer-controlled
+func (p *Player) IsAI() bool {
+	return IsAI(p.ID)
 }
 
 // Adds new turn duration to the average, should be called afer incrementing amount of cards played
@@ -58,11 +79,12 @@ func (p *Player) AddDuration(t time.Duration) {
 	p.AvgRespTime = time.Duration((int64(p.CardsPlayed-1)*p.AvgRespTime.Nanoseconds() + t.Nanoseconds()) / int64(p.CardsPlayed))
 }
 
-func (p *Player) CurrentHandPoints() int {
+// CurrentHandPoints returns how many points the cards on the player's hand are worth
+func (p *Player) CurrentHandPoints(table deck.ScoreTable) int {
 	sum := 0
 
 	for _, c := range p.Hand {
-		sum += c.Score()
+		sum += c.Score(table)
 	}
 
 	return sum
@@ -0,0 +1,76 @@
+package game
+
+import (
+	"strings"
+
+	"github.com/d-nery/catorce/pkg/deck"
+)
+
+// RecentPlaysSize is how many plays are kept in Game.RecentPlays
+const RecentPlaysSize = 5
+
+// Play is a public record of a move, it never holds hidden information (e.g. which cards were drawn)
+type Play struct {
+	Player string
+	Event  string     // Same names as the journal entries
+	Card   *deck.Card `json:",omitempty"`
+	Color  deck.Color `json:",omitempty"`
+	Target string     `json:",omitempty"`
+	Drawn  int        `json:",omitempty"` // Amount of cards drawn
+}
+
+// playFor creates the public play for evt, ok is false for events that aren't moves
+func (g *Game) playFor(evt interface{}) (play Play, ok bool) {
+	switch e := evt.(type) {
+	case *EvtCardPlayed:
+		play = Play{Player: e.Player.Name, Card: &deck.Card{ID: e.Card.ID, Color: e.Card.Color, Type: e.Card.Type, Value: e.Card.Value}}
+	case *EvtColorChosen:
+		play = Play{Player: e.Player.Name, Color: e.Color}
+	case *EvtPlayerSwapChosen:
+		play = Play{Player: e.Player.Name}
+		if target := g.GetPlayer(e.Target); target != nil {
+			play.Target = target.Name
+		}
+	case *EvtDrawCard:
+		play = Play{Player: e.Player.Name}
+	case *EvtChallenge:
+		play = Play{Player: e.Player.Name}
+	case *EvtPass:
+		play = Play{Player: e.Player.Name}
+	case *EvtCatorce:
+		play = Play{Player: e.Player.Name}
+	case *EvtCatchCatorce:
+		play = Play{Player: e.Player.Name}
+		play.Target = PlayerNames(e.Caught)
+	default:
+		return play, false
+	}
+
+	play.Event = journalEntry(evt).Event
+	return play, true
+}
+
+// addPlay adds a play to the recent plays, dropping the oldest one if needed
+// mark is the deck log position before the play, see drawMark
+func (g *Game) addPlay(play Play, mark int) {
+	if g.muted {
+		return
+	}
+
+	play.Drawn = len(g.drawnSince(mark))
+
+	g.RecentPlays = append(g.RecentPlays, play)
+	if len(g.RecentPlays) > RecentPlaysSize {
+		g.RecentPlays = g.RecentPlays[len(g.RecentPlays)-RecentPlaysSize:]
+	}
+}
+
+// PlayerNames joins the names of players, in order
+func PlayerNames(players []*Player) string {
+	names := make([]string, len(players))
+	for i, p := range players {
+		names[i] = p.Name
+	}
+
+	return strings.Join(names, ", ")
+}
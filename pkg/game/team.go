@@ -0,0 +1,105 @@
+package game
+
+// Possible teams, players only have a team when the game is in team mode
+const (
+	NO_TEAM = iota
+	TEAM_A
+	TEAM_B
+)
+
+// TeamMembers returns all players in team
+func (g *Game) TeamMembers(team int) []*Player {
+	members := []*Player{}
+
+	for _, p := range g.Players {
+		if p.Team == team {
+			members = append(members, p)
+		}
+	}
+
+	return members
+}
+
+// SmallestTeam returns the team with less players, TEAM_A on ties
+func (g *Game) SmallestTeam() int {
+	if len(g.TeamMembers(TEAM_B)) < len(g.TeamMembers(TEAM_A)) {
+		return TEAM_B
+	}
+
+	return TEAM_A
+}
+
+// SetTeam moves p to team
+func (g *Game) SetTeam(p *Player, team int) {
+	g.logger.Trace().Int("pid", p.ID).Int("team", team).Msg("Setting player team")
+	p.Team = team
+}
+
+// BalanceTeams assigns every player without a team to the smallest team
+func (g *Game) BalanceTeams() {
+	for _, p := range g.Players {
+		if p.Team == NO_TEAM {
+			g.SetTeam(p, g.SmallestTeam())
+		}
+	}
+}
+
+// TeamsBalanced checks if both teams have players and the same amount of them
+func (g *Game) TeamsBalanced() bool {
+	a, b := len(g.TeamMembers(TEAM_A)), len(g.TeamMembers(TEAM_B))
+	return a > 0 && a == b
+}
+
+// TeamsCanBalance checks if the teams would be balanced after BalanceTeams
+func (g *Game) TeamsCanBalance() bool {
+	a, b := len(g.TeamMembers(TEAM_A)), len(g.TeamMembers(TEAM_B))
+
+	for range g.TeamMembers(NO_TEAM) {
+		if b < a {
+			b++
+		} else {
+			a++
+		}
+	}
+
+	return a > 0 && a == b
+}
+
+// SeatTeams orders the players alternating teams, keeping each team's relative order
+// The starting team is random
+func (g *Game) SeatTeams() {
+	teams := [][]*Player{g.TeamMembers(TEAM_A), g.TeamMembers(TEAM_B)}
+
+	if g.Rand().Intn(2) == 1 {
+		teams[0], teams[1] = teams[1], teams[0]
+	}
+
+	g.Players = g.Players[:0]
+	for i := 0; i < len(teams[0]) || i < len(teams[1]); i++ {
+		for _, t := range teams {
+			if i < len(t) {
+				g.Players = append(g.Players, t[i])
+			}
+		}
+	}
+}
+
+// WinningTeam returns the winner's team, NO_TEAM if there's no winner or the game isn't in team mode
+func (g *Game) WinningTeam() int {
+	if p := g.GetPlayer(g.Winner); p != nil {
+		return p.Team
+	}
+
+	return NO_TEAM
+}
+
+// TeamPoints returns the sum of the points on the hands of every player in team
+func (g *Game) TeamPoints(team int) int {
+	sum := 0
+
+	for _, p := range g.TeamMembers(team) {
+		sum += p.CurrentHandPoints(g.Config.Scores)
+	}
+
+	return sum
+}
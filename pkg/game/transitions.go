@@ -0,0 +1,184 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Transition is a row of the game state machine: in state From, the event named Event is checked by its guard,
+// applied by its action and leaves the game in one of the states in To
+type Transition struct {
+	From  GameState
+	Event string
+	To    []GameState
+
+	guard  func(g *Game, evt interface{}) EventError
+	action func(g *Game, evt interface{})
+}
+
+// stay stands for the state a transition comes from when listing where it goes
+const stay GameState = ""
+
+// Running states, the game is being played
+var running = []GameState{CHOOSE_CARD, DREW, CHOOSE_COLOR, CHOOSE_PLAYER, CHALLENGE}
+
+// Transitions is the game state machine, see FireEvent
+var Transitions = concat(
+	on(States, (*Game).guardAddPlayer, (*Game).addPlayer, stay),
+	on([]GameState{LOBBY}, (*Game).guardRemovePlayer, (*Game).removePlayer, stay),
+	on(running, (*Game).guardRemovePlayer, (*Game).removePlayer, stay, CHOOSE_CARD, LOBBY),
+	on([]GameState{LOBBY}, (*Game).guardStartGame, (*Game).startGame, CHOOSE_CARD),
+	on([]GameState{LOBBY}, (*Game).guardChooseTeam, (*Game).chooseTeam, stay),
+	on([]GameState{CHOOSE_CARD, DREW, CHALLENGE}, (*Game).guardCardPlayed, (*Game).cardPlayed,
+		CHOOSE_CARD, CHOOSE_COLOR, CHOOSE_PLAYER, LOBBY),
+	on([]GameState{CHOOSE_CARD, CHALLENGE}, (*Game).guardDrawCard, (*Game).drawCard, DREW, CHOOSE_CARD),
+	on([]GameState{CHOOSE_CARD, CHALLENGE}, (*Game).guardCatorce, (*Game).catorce, stay),
+	on([]GameState{CHOOSE_CARD, CHALLENGE}, (*Game).guardCatchCatorce, (*Game).catchCatorce, stay),
+	on([]GameState{DREW}, nil, (*Game).pass, CHOOSE_CARD),
+	on([]GameState{CHOOSE_COLOR}, (*Game).guardColorChosen, (*Game).colorChosen,
+		CHOOSE_CARD, CHALLENGE, CHOOSE_PLAYER, LOBBY),
+	on([]GameState{CHOOSE_PLAYER}, (*Game).guardPlayerSwapChosen, (*Game).playerSwapChosen, CHOOSE_CARD, CHALLENGE),
+	on([]GameState{CHALLENGE}, (*Game).guardChallenge, (*Game).challenge, CHOOSE_CARD),
+)
+
+// transitionTable indexes Transitions by state and event
+var transitionTable = map[GameState]map[string]*Transition{}
+
+// eventNames has every event the state machine knows
+var eventNames = map[string]bool{}
+
+// Possible state machine errors, found when validating the transitions
+var (
+	ErrUnknownState       = errors.New("fsm: transition to an unknown state")
+	ErrDuplicateEvent     = errors.New("fsm: event has more than one transition in a state")
+	ErrUnreachableState   = errors.New("fsm: state can't be reached from LOBBY")
+	ErrNoTransitionsState = errors.New("fsm: state has no transitions")
+)
+
+func init() {
+	if err := ValidateTransitions(Transitions); err != nil {
+		panic(err)
+	}
+
+	for i := range Transitions {
+		t := &Transitions[i]
+
+		if transitionTable[t.From] == nil {
+			transitionTable[t.From] = map[string]*Transition{}
+		}
+
+		transitionTable[t.From][t.Event] = t
+		eventNames[t.Event] = true
+	}
+}
+
+// on creates the transitions of event E from each of the states in from
+// A nil guard accepts every event, stay in to is replaced by the state the transition comes from
+func on[E any](from []GameState, guard func(*Game, *E) EventError, action func(*Game, *E), to ...GameState) []Transition {
+	ts := make([]Transition, 0, len(from))
+
+	for _, f := range from {
+		t := Transition{
+			From:  f,
+			Event: EventName(new(E)),
+			guard: func(g *Game, evt interface{}) EventError {
+				if guard == nil {
+					return nil
+				}
+
+				return guard(g, evt.(*E))
+			},
+			action: func(g *Game, evt interface{}) {
+				action(g, evt.(*E))
+			},
+		}
+
+		for _, s := range to {
+			if s == stay {
+				s = f
+			}
+
+			t.To = append(t.To, s)
+		}
+
+		ts = append(ts, t)
+	}
+
+	return ts
+}
+
+// concat joins groups of transitions in order
+func concat(groups ...[]Transition) []Transition {
+	var ts []Transition
+
+	for _, g := range groups {
+		ts = append(ts, g...)
+	}
+
+	return ts
+}
+
+// ValidateTransitions checks that the transitions only use known states, have a single row for each state and event,
+// and that every state can be reached from LOBBY and left
+func ValidateTransitions(ts []Transition) error {
+	known := map[GameState]bool{}
+	for _, s := range States {
+		known[s] = true
+	}
+
+	seen := map[string]bool{}
+	next := map[GameState][]GameState{}
+
+	for _, t := range ts {
+		if !known[t.From] {
+			return fmt.Errorf("%w: %s", ErrUnknownState, t.From)
+		}
+
+		key := string(t.From) + " " + t.Event
+		if seen[key] {
+			return fmt.Errorf("%w: %s", ErrDuplicateEvent, key)
+		}
+		seen[key] = true
+
+		for _, to := range t.To {
+			if !known[to] {
+				return fmt.Errorf("%w: %s on %s", ErrUnknownState, to, key)
+			}
+
+			next[t.From] = append(next[t.From], to)
+		}
+	}
+
+	reached := map[GameState]bool{LOBBY: true}
+	queue := []GameState{LOBBY}
+
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+
+		for _, to := range next[s] {
+			if !reached[to] {
+				reached[to] = true
+				queue = append(queue, to)
+			}
+		}
+	}
+
+	for _, s := range States {
+		if !reached[s] {
+			return fmt.Errorf("%w: %s", ErrUnreachableState, s)
+		}
+
+		if len(next[s]) == 0 {
+			return fmt.Errorf("%w: %s", ErrNoTransitionsState, s)
+		}
+	}
+
+	return nil
+}
+
+// eventLabel is the name of an event in diagrams
+func eventLabel(event string) string {
+	return strings.TrimPrefix(event, "Evt")
+}
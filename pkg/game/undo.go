@@ -0,0 +1,85 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+)
+
+// UndoHistory is how many moves can be undone
+const UndoHistory = 10
+
+// ErrNothingToUndo is returned when there's no move in the undo history
+var ErrNothingToUndo = errors.New("game: nothing to undo")
+
+// DisableUndo stops keeping snapshots, which are costly when games don't need undo (e.g. simulations)
+func (g *Game) DisableUndo() {
+	g.noUndo = true
+	g.history = nil
+}
+
+// snapshot serializes the game state, nil while muted as the enclosing move takes its own
+func (g *Game) snapshot() []byte {
+	if g.muted || g.noUndo {
+		return nil
+	}
+
+	body, err := json.Marshal(g)
+	if err != nil {
+		g.logger.Error().Err(err).Msg("Failed to snapshot game")
+		return nil
+	}
+
+	return body
+}
+
+// pushHistory adds a snapshot to the undo history, dropping the oldest one if it's full
+func (g *Game) pushHistory(snap []byte) {
+	if snap == nil {
+		return
+	}
+
+	g.history = append(g.history, snap)
+
+	if len(g.history) > UndoHistory {
+		g.history = g.history[len(g.history)-UndoHistory:]
+	}
+}
+
+// CanUndo checks if there's a move to undo
+func (g *Game) CanUndo() bool {
+	return len(g.history) > 0
+}
+
+// Undo restores the game to the snapshot before the last accepted move
+// Deck, hands, counters and turn order are restored, the config, logger and journal are kept
+func (g *Game) Undo() error {
+	if !g.CanUndo() {
+		return ErrNothingToUndo
+	}
+
+	snap := g.history[len(g.history)-1]
+
+	var restored Game
+	if err := json.Unmarshal(snap, &restored); err != nil {
+		return err
+	}
+
+	g.logger.Debug().Str("from", string(g.State)).Str("to", string(restored.State)).Msg("Undoing last move")
+
+	config := g.Config
+
+	dst, src := reflect.ValueOf(g).Elem(), reflect.ValueOf(&restored).Elem()
+	for i := 0; i < dst.NumField(); i++ {
+		if dst.Type().Field(i).IsExported() {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+
+	g.Config = config
+	g.history = g.history[:len(g.history)-1]
+	// Undoing draws nothing, the mark is taken from the restored deck log
+	g.record(JournalEntry{Event: JournalUndo}, g.drawMark())
+
+	return nil
+}
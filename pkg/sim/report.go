@@ -0,0 +1,155 @@
+package sim
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Report holds the results of a simulation
+type Report struct {
+	Options
+	Results []Result
+}
+
+// Finished returns the results of the games that finished
+func (r *Report) Finished() []Result {
+	finished := make([]Result, 0, len(r.Results))
+
+	for _, res := range r.Results {
+		if res.Finished {
+			finished = append(finished, res)
+		}
+	}
+
+	return finished
+}
+
+// Distribution summarizes a list of values
+type Distribution struct {
+	Min, Max, Median, P10, P90 int
+	Mean                       float64
+}
+
+// distribution summarizes the value of every result
+func distribution(results []Result, value func(Result) int) Distribution {
+	if len(results) == 0 {
+		return Distribution{}
+	}
+
+	values := make([]int, 0, len(results))
+	sum := 0
+
+	for _, res := range results {
+		values = append(values, value(res))
+		sum += value(res)
+	}
+
+	sort.Ints(values)
+	at := func(p int) int {
+		return values[(len(values)-1)*p/100]
+	}
+
+	return Distribution{
+		Min:    values[0],
+		Max:    values[len(values)-1],
+		Median: at(50),
+		P10:    at(10),
+		P90:    at(90),
+		Mean:   float64(sum) / float64(len(values)),
+	}
+}
+
+func (d Distribution) String() string {
+	return fmt.Sprintf("mean %.1f, min %d, p10 %d, median %d, p90 %d, max %d", d.Mean, d.Min, d.P10, d.Median, d.P90, d.Max)
+}
+
+// histogram writes a text histogram of the values of every result, grouped in buckets of size width
+func histogram(out *strings.Builder, results []Result, width int, value func(Result) int) {
+	buckets := map[int]int{}
+	most := 0
+
+	for _, res := range results {
+		b := value(res) / width
+		buckets[b] += 1
+
+		if buckets[b] > most {
+			most = buckets[b]
+		}
+	}
+
+	keys := make([]int, 0, len(buckets))
+	for b := range buckets {
+		keys = append(keys, b)
+	}
+	sort.Ints(keys)
+
+	for _, b := range keys {
+		label := fmt.Sprint(b * width)
+		if width > 1 {
+			label = fmt.Sprintf("%d-%d", b*width, (b+1)*width-1)
+		}
+
+		fmt.Fprintf(out, "  %9s | %-40s %d\n", label, strings.Repeat("#", buckets[b]*40/most), buckets[b])
+	}
+}
+
+// String formats the report as text
+func (r *Report) String() string {
+	var out strings.Builder
+	finished := r.Finished()
+
+	fmt.Fprintf(&out, "Games: %d (%d finished), players: %d, strategies: %s, seed: %d\n\n",
+		len(r.Results), len(finished), r.Players, strings.Join(r.Strategies, ","), r.Seed)
+
+	if len(finished) == 0 {
+		out.WriteString("No game finished\n")
+		return out.String()
+	}
+
+	rounds := func(res Result) int { return res.Rounds }
+	fmt.Fprintf(&out, "Rounds: %s\n", distribution(finished, rounds))
+	histogram(&out, finished, 10, rounds)
+
+	fmt.Fprintf(&out, "\nWinner seat:\n")
+	seats := make([]int, r.Players)
+	for _, res := range finished {
+		seats[res.WinnerSeat] += 1
+	}
+	for i, wins := range seats {
+		fmt.Fprintf(&out, "  %9d | %5.1f%% (%d)\n", i, 100*float64(wins)/float64(len(finished)), wins)
+	}
+
+	expected := 100 / float64(r.Players)
+	first := 100 * float64(seats[0]) / float64(len(finished))
+	fmt.Fprintf(&out, "\nFirst player advantage: %+.1f points (wins %.1f%%, fair share %.1f%%)\n", first-expected, first, expected)
+
+	chain := func(res Result) int { return res.LargestChain }
+	fmt.Fprintf(&out, "\nLargest draw chain: %s\n", distribution(finished, chain))
+	histogram(&out, finished, 1, chain)
+
+	reshuffles := func(res Result) int { return res.Reshuffles }
+	fmt.Fprintf(&out, "\nDeck reshuffles: %s\n", distribution(finished, reshuffles))
+	histogram(&out, finished, 1, reshuffles)
+
+	if len(r.Strategies) > 1 {
+		fmt.Fprintf(&out, "\nWins by strategy:\n")
+
+		wins := map[string]int{}
+		for _, res := range finished {
+			wins[res.Strategy] += 1
+		}
+
+		names := make([]string, 0, len(wins))
+		for name := range wins {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Fprintf(&out, "  %9s | %5.1f%% (%d)\n", name, 100*float64(wins[name])/float64(len(finished)), wins[name])
+		}
+	}
+
+	return out.String()
+}
@@ -0,0 +1,149 @@
+// Package sim runs headless games between computer-controlled players, to see how rule changes affect games
+package sim
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/d-nery/catorce/pkg/ai"
+	"github.com/d-nery/catorce/pkg/deck"
+	"github.com/d-nery/catorce/pkg/game"
+	"github.com/rs/zerolog"
+)
+
+// MaxMoves is how many moves a game can take before it's considered stuck
+const MaxMoves = 10000
+
+// Possible simulation errors
+var (
+	ErrNoGames          = errors.New("sim: at least one game is needed")
+	ErrUnknownStrategy  = errors.New("sim: unknown strategy")
+	ErrNoStrategies     = errors.New("sim: at least one strategy is needed")
+	ErrTooFewPlayers    = errors.New("sim: not enough players for the config")
+	ErrTooManyPlayers   = errors.New("sim: too many players for the config")
+	ErrInvalidSimConfig = errors.New("sim: invalid config")
+)
+
+// Options configures a simulation
+type Options struct {
+	Games      int
+	Players    int
+	Strategies []string // Assigned to players in join order, repeating if there are more players
+	Config     *game.Config
+	Seed       int64 // Game i is seeded with Seed+i, 0 picks a random one
+}
+
+// Result holds the outcome of a single simulated game
+type Result struct {
+	Seed         int64
+	Finished     bool
+	Rounds       int
+	Moves        int
+	WinnerSeat   int    // Position of the winner in join order, 0 is the first player to join
+	Strategy     string // Strategy of the winner
+	LargestChain int    // Largest amount of cards accumulated by stacked draws
+	Reshuffles   int    // Times the graveyard was shuffled back, or a half deck was added
+}
+
+// Validate checks the options
+func (o *Options) Validate() error {
+	if o.Games < 1 {
+		return ErrNoGames
+	}
+
+	if len(o.Strategies) == 0 {
+		return ErrNoStrategies
+	}
+
+	for _, s := range o.Strategies {
+		if _, ok := ai.Strategies[s]; !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownStrategy, s)
+		}
+	}
+
+	if err := o.Config.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSimConfig, err)
+	}
+
+	if o.Players < o.Config.MinPlayers {
+		return ErrTooFewPlayers
+	}
+
+	if o.Players > o.Config.MaxPlayers {
+		return ErrTooManyPlayers
+	}
+
+	return nil
+}
+
+// Run runs the simulation
+func Run(o Options) (*Report, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+
+	if o.Seed == 0 {
+		o.Seed = deck.RandomSeed()
+	}
+
+	report := &Report{Options: o, Results: make([]Result, 0, o.Games)}
+
+	for i := 0; i < o.Games; i++ {
+		res, err := Play(o, o.Seed+int64(i))
+		if err != nil {
+			return nil, err
+		}
+
+		report.Results = append(report.Results, res)
+	}
+
+	return report, nil
+}
+
+// Play simulates a single game with seed
+func Play(o Options, seed int64) (Result, error) {
+	res := Result{Seed: seed}
+
+	g := game.New(0, zerolog.Nop(), o.Config)
+	g.DisableUndo()
+
+	// Seats are taken in join order, starting the game may shuffle players and the first card may move the turn
+	seats := map[int]int{}
+
+	for i := 1; i <= o.Players; i++ {
+		p := game.NewPlayer(-i, fmt.Sprintf("Robô %d", i), "")
+		seats[p.ID] = i - 1
+		p.Strategy = o.Strategies[(i-1)%len(o.Strategies)]
+
+		if err := g.FireEvent(&game.EvtAddPlayer{Player: p}); err != nil {
+			return res, err
+		}
+	}
+
+	if err := g.FireEvent(&game.EvtStartGame{Seed: seed}); err != nil {
+		return res, err
+	}
+
+	for ; res.Moves < MaxMoves && g.GetState() != game.LOBBY; res.Moves++ {
+		if _, err := ai.Move(g); err != nil {
+			return res, fmt.Errorf("sim: game %d, move %d: %w", seed, res.Moves, err)
+		}
+	}
+
+	res.Finished = g.GetState() == game.LOBBY
+	res.Rounds = g.Rounds
+	res.LargestChain = g.P2Sequence
+
+	for _, e := range g.Deck.Log {
+		if len(e.Refill) > 0 || e.Merge {
+			res.Reshuffles += 1
+		}
+	}
+
+	if winner := g.GetPlayer(g.Winner); res.Finished && winner != nil {
+		res.WinnerSeat = seats[winner.ID]
+		res.Strategy = winner.Strategy
+	}
+
+	return res, nil
+}
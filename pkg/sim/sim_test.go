@@ -0,0 +1,64 @@
+package sim
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/d-nery/catorce/pkg/game"
+)
+
+func TestRun(t *testing.T) {
+	o := Options{Games: 20, Players: 3, Strategies: []string{"facil", "dificil"}, Config: game.DefaultConfig(), Seed: 7}
+
+	report, err := Run(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Results) != o.Games || len(report.Finished()) != o.Games {
+		t.Fatalf("%d games played and %d finished, want %d", len(report.Results), len(report.Finished()), o.Games)
+	}
+
+	for i, res := range report.Results {
+		if res.Seed != o.Seed+int64(i) {
+			t.Errorf("game %d has seed %d, want %d", i, res.Seed, o.Seed+int64(i))
+		}
+
+		// Strategies are assigned in join order
+		if res.WinnerSeat < 0 || res.WinnerSeat >= o.Players || res.Strategy != o.Strategies[res.WinnerSeat%len(o.Strategies)] {
+			t.Errorf("game %d was won by seat %d playing %q", i, res.WinnerSeat, res.Strategy)
+		}
+	}
+
+	again, err := Run(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(report.Results, again.Results) {
+		t.Error("the same seed gave different results")
+	}
+}
+
+func TestRunInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		o    Options
+		err  error
+	}{
+		{"no games", Options{Players: 2, Strategies: []string{"facil"}}, ErrNoGames},
+		{"no strategies", Options{Games: 1, Players: 2}, ErrNoStrategies},
+		{"unknown strategy", Options{Games: 1, Players: 2, Strategies: []string{"facil", "x"}}, ErrUnknownStrategy},
+		{"too few players", Options{Games: 1, Players: 1, Strategies: []string{"facil"}}, ErrTooFewPlayers},
+		{"too many players", Options{Games: 1, Players: 11, Strategies: []string{"facil"}}, ErrTooManyPlayers},
+	}
+
+	for _, tt := range tests {
+		tt.o.Config = game.DefaultConfig()
+
+		if _, err := Run(tt.o); !errors.Is(err, tt.err) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}